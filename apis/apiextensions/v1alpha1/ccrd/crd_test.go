@@ -390,6 +390,13 @@ func TestPublishesInfrastructureDefinition(t *testing.T) {
 										},
 									},
 								},
+								"compositeDeletePolicy": {
+									Type: "string",
+									Enum: []v1beta1.JSON{
+										{Raw: []byte(`"Background"`)},
+										{Raw: []byte(`"Orphan"`)},
+									},
+								},
 								"resourceRef": {
 									Type:     "object",
 									Required: []string{"name"},
@@ -402,6 +409,13 @@ func TestPublishesInfrastructureDefinition(t *testing.T) {
 									Required: []string{"name"},
 									Properties: map[string]v1beta1.JSONSchemaProps{
 										"name": {Type: "string"},
+										"keyMap": {
+											Type: "object",
+											AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
+												Allows: true,
+												Schema: &v1beta1.JSONSchemaProps{Type: "string"},
+											},
+										},
 									},
 								},
 							},
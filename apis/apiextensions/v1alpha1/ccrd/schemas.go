@@ -137,6 +137,13 @@ func PublishedInfrastructureSpecProps() map[string]v1beta1.JSONSchemaProps {
 				},
 			},
 		},
+		"compositeDeletePolicy": {
+			Type: "string",
+			Enum: []v1beta1.JSON{
+				{Raw: []byte(`"Background"`)},
+				{Raw: []byte(`"Orphan"`)},
+			},
+		},
 		"resourceRef": {
 			Type:     "object",
 			Required: []string{"name"},
@@ -149,6 +156,13 @@ func PublishedInfrastructureSpecProps() map[string]v1beta1.JSONSchemaProps {
 			Required: []string{"name"},
 			Properties: map[string]v1beta1.JSONSchemaProps{
 				"name": {Type: "string"},
+				"keyMap": {
+					Type: "object",
+					AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
+						Allows: true,
+						Schema: &v1beta1.JSONSchemaProps{Type: "string"},
+					},
+				},
 			},
 		},
 	}
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -32,6 +33,7 @@ import (
 const (
 	errMathNoMultiplier   = "no input is given"
 	errMathInputNonNumber = "input is required to be a number for math transformer"
+	errAggregateToStatus  = "status aggregations must write to a status field"
 )
 
 var (
@@ -54,6 +56,12 @@ type CompositionSpec struct {
 	// To is the list of target resources that make up the composition.
 	To []ComposedTemplate `json:"to"`
 
+	// AllowEmpty indicates that this composition may intentionally compose no
+	// resources. A composite resource that uses a composition with no target
+	// resources is considered ready only if AllowEmpty is true.
+	// +optional
+	AllowEmpty bool `json:"allowEmpty,omitempty"`
+
 	// ReclaimPolicy specifies what will happen to composite resource dynamically
 	// provisioned using this composition when their namespaced referrer is deleted.
 	// The "Delete" policy causes the composite resource to be deleted
@@ -70,6 +78,104 @@ type CompositionSpec struct {
 	// connection secrets of composite resource dynamically provisioned using
 	// this composition will be created.
 	WriteConnectionSecretsToNamespace string `json:"writeConnectionSecretsToNamespace"`
+
+	// ConnectionDetails configures how the connection details of the target
+	// resources are propagated to the composite resource connection secret.
+	// +optional
+	ConnectionDetails *CompositionConnectionDetails `json:"connectionDetails,omitempty"`
+
+	// StatusAggregations summarise a field of each of the target resources
+	// into the status of the composite resource.
+	// +optional
+	StatusAggregations []StatusAggregation `json:"statusAggregations,omitempty"`
+
+	// ReadinessPollInterval is how often a composite resource using this
+	// composition is reconciled while any of its target resources are not
+	// yet ready. A composite resource is polled more often while none of its
+	// target resources are ready than while only some are if no interval is
+	// specified.
+	// +optional
+	ReadinessPollInterval *metav1.Duration `json:"readinessPollInterval,omitempty"`
+
+	// PropagateLabels are the keys of labels of the composite resource that
+	// are propagated to all of its target resources. A label that is set by
+	// the base or the patches of a target resource takes precedence over a
+	// propagated label.
+	// +optional
+	PropagateLabels []string `json:"propagateLabels,omitempty"`
+}
+
+// A StatusAggregation collects the value of a field of each target resource
+// into an array in the status of the composite resource.
+type StatusAggregation struct {
+	// FromFieldPath is the path of the field of each target resource whose
+	// value is collected. Target resources that do not have the field are
+	// skipped.
+	FromFieldPath string `json:"fromFieldPath"`
+
+	// ToFieldPath is the path of the field of the composite resource that
+	// the collected values are written to, as an array in the order of the
+	// target resources. It must be a status field.
+	ToFieldPath string `json:"toFieldPath"`
+}
+
+// Aggregate collects the value of the FromFieldPath of each of the supplied
+// composed resources into the ToFieldPath of the supplied composite resource.
+func (a *StatusAggregation) Aggregate(from []runtime.Object, to runtime.Object) error {
+	if !strings.HasPrefix(a.ToFieldPath, "status.") {
+		return errors.New(errAggregateToStatus)
+	}
+
+	values := make([]interface{}, 0, len(from))
+	for _, o := range from {
+		fromMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+		if err != nil {
+			return err
+		}
+		v, err := fieldpath.Pave(fromMap).GetValue(a.FromFieldPath)
+		if fieldpath.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+
+	if u, ok := to.(interface{ UnstructuredContent() map[string]interface{} }); ok {
+		return fieldpath.Pave(u.UnstructuredContent()).SetValue(a.ToFieldPath, values)
+	}
+
+	toMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(to)
+	if err != nil {
+		return err
+	}
+	if err := fieldpath.Pave(toMap).SetValue(a.ToFieldPath, values); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(toMap, to)
+}
+
+// CompositionConnectionDetails configures how connection details are
+// propagated to the composite resource connection secret.
+type CompositionConnectionDetails struct {
+	// Filter limits which connection secret keys are propagated. All keys are
+	// propagated if no filter is specified.
+	// +optional
+	Filter *ConnectionDetailsFilter `json:"filter,omitempty"`
+}
+
+// ConnectionDetailsFilter limits which connection secret keys are propagated
+// to the composite resource connection secret.
+type ConnectionDetailsFilter struct {
+	// Allow lists the only keys that will be propagated. All keys are allowed
+	// if this list is empty.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists keys that will not be propagated, even if they are allowed.
+	// +optional
+	Deny []string `json:"deny,omitempty"`
 }
 
 // TypeReference is used to refer to a type for declaring compatibility.
@@ -95,6 +201,100 @@ type ComposedTemplate struct {
 	// resource to the composition instance connection secret.
 	// +optional
 	ConnectionDetails []ConnectionDetail `json:"connectionDetails,omitempty"`
+
+	// ReadinessCheck determines how to tell whether this target resource is
+	// ready. A target resource is ready when its Ready condition is True if
+	// no readiness check is specified.
+	// +optional
+	ReadinessCheck *ReadinessCheck `json:"readinessCheck,omitempty"`
+
+	// IgnoreFields are paths to fields of this target resource that are set
+	// when it is created, but are not applied again afterwards. This allows
+	// them to be tuned without the composition reverting them. Fields within
+	// arrays may not be ignored.
+	// +optional
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+
+	// ObserveOnly target resources are managed outside of the composition.
+	// They are read, such that their connection details and readiness are
+	// surfaced, but they are never created, updated, or deleted. An observe
+	// only target resource must be named, either by its base or by a patch.
+	// +optional
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+
+	// NameTemplate is a Go template used to name this target resource when it
+	// is created, instead of generating a name. The fields of the composite
+	// resource are available to the template, e.g. "{{ .metadata.name }}-db".
+	// A target resource whose rendered name is already taken by a resource
+	// that is controlled by another composite resource will not be composed.
+	// +optional
+	NameTemplate *string `json:"nameTemplate,omitempty"`
+
+	// Condition determines whether this target resource is composed. A target
+	// resource is always composed if no condition is specified. A target
+	// resource that was composed is deleted if its condition becomes false.
+	// +optional
+	Condition *TemplateCondition `json:"condition,omitempty"`
+}
+
+// A TemplateCondition is met when a field of the composite resource has the
+// specified value.
+type TemplateCondition struct {
+	// FieldPath is the path of the field of the composite resource whose value
+	// is checked. The condition is not met if the field does not exist.
+	FieldPath string `json:"fieldPath"`
+
+	// Equals is the value the field must have for the condition to be met.
+	// Values that are not strings, such as "true" or "3", are compared using
+	// their string representation.
+	Equals string `json:"equals"`
+}
+
+// Met returns true if the supplied composite resource meets the condition.
+func (c *TemplateCondition) Met(cp runtime.Object) (bool, error) {
+	cpMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cp)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := fieldpath.Pave(cpMap).GetValue(c.FieldPath)
+	if fieldpath.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return fmt.Sprint(v) == c.Equals, nil
+}
+
+// ReadinessCheckType is the type of a readiness check.
+type ReadinessCheckType string
+
+// Accepted ReadinessCheckTypes.
+const (
+	ReadinessCheckMatchCondition ReadinessCheckType = "MatchCondition"
+	ReadinessCheckNonEmpty       ReadinessCheckType = "NonEmpty"
+	ReadinessCheckNone           ReadinessCheckType = "None"
+)
+
+// ReadinessCheck is used to determine whether a target resource is ready.
+type ReadinessCheck struct {
+	// Type of the readiness check. MatchCondition checks that a condition of
+	// the target resource is True. NonEmpty checks that a field of the target
+	// resource is set. None considers the target resource ready as soon as it
+	// exists.
+	// +kubebuilder:validation:Enum=MatchCondition;NonEmpty;None
+	Type ReadinessCheckType `json:"type"`
+
+	// ConditionType is the type of the condition that must be True for the
+	// MatchCondition check to pass. Defaults to Ready.
+	// +optional
+	ConditionType v1alpha1.ConditionType `json:"conditionType,omitempty"`
+
+	// FieldPath is the path of the field that must be set for the NonEmpty
+	// check to pass.
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
 }
 
 // Patch is used to patch the field on the base resource at ToFieldPath
@@ -21,6 +21,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
@@ -192,3 +194,125 @@ func TestStringResolve(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusAggregationAggregate(t *testing.T) {
+	composed := func(endpoint string) runtime.Object {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if endpoint != "" {
+			u.Object["status"] = map[string]interface{}{
+				"atProvider": map[string]interface{}{"endpoint": endpoint},
+			}
+		}
+		return u
+	}
+
+	type args struct {
+		a    StatusAggregation
+		from []runtime.Object
+	}
+	type want struct {
+		o   map[string]interface{}
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"Aggregated": {
+			reason: "The value of the field of each composed resource should be written to the composite, skipping those without it",
+			args: args{
+				a:    StatusAggregation{FromFieldPath: "status.atProvider.endpoint", ToFieldPath: "status.endpoints"},
+				from: []runtime.Object{composed("a.example.org"), composed(""), composed("b.example.org")},
+			},
+			want: want{
+				o: map[string]interface{}{
+					"status": map[string]interface{}{
+						"endpoints": []interface{}{"a.example.org", "b.example.org"},
+					},
+				},
+			},
+		},
+		"NoneFound": {
+			reason: "An empty array should be written when no composed resource has the field",
+			args: args{
+				a:    StatusAggregation{FromFieldPath: "status.atProvider.endpoint", ToFieldPath: "status.endpoints"},
+				from: []runtime.Object{composed("")},
+			},
+			want: want{
+				o: map[string]interface{}{
+					"status": map[string]interface{}{
+						"endpoints": []interface{}{},
+					},
+				},
+			},
+		},
+		"NotStatus": {
+			reason: "Aggregations may only write to the status of the composite",
+			args: args{
+				a:    StatusAggregation{FromFieldPath: "status.atProvider.endpoint", ToFieldPath: "spec.endpoints"},
+				from: []runtime.Object{composed("a.example.org")},
+			},
+			want: want{
+				o:   map[string]interface{}{},
+				err: errors.New(errAggregateToStatus),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			to := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			err := tc.args.a.Aggregate(tc.args.from, to)
+
+			if diff := cmp.Diff(tc.want.o, to.Object); diff != "" {
+				t.Errorf("\n%s\nAggregate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAggregate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTemplateConditionMet(t *testing.T) {
+	cp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"highAvailability": true,
+			"tier":             "gold",
+		},
+	}}
+
+	cases := map[string]struct {
+		reason string
+		c      TemplateCondition
+		want   bool
+	}{
+		"BoolMet": {
+			reason: "A boolean field should be compared using its string representation",
+			c:      TemplateCondition{FieldPath: "spec.highAvailability", Equals: "true"},
+			want:   true,
+		},
+		"StringNotMet": {
+			reason: "A condition should not be met if the field has a different value",
+			c:      TemplateCondition{FieldPath: "spec.tier", Equals: "silver"},
+			want:   false,
+		},
+		"MissingField": {
+			reason: "A condition should not be met if the field does not exist",
+			c:      TemplateCondition{FieldPath: "spec.replicas", Equals: "3"},
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.c.Met(cp)
+			if err != nil {
+				t.Fatalf("\n%s\nMet(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
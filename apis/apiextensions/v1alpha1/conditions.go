@@ -27,6 +27,10 @@ import (
 const (
 	// TypeReady resources are believed to be ready to handle work.
 	TypeEstablished runtimev1alpha1.ConditionType = "Established"
+
+	// TypeCompositionSelected composite resources have selected a
+	// composition using their composition selector.
+	TypeCompositionSelected runtimev1alpha1.ConditionType = "CompositionSelected"
 )
 
 // Reasons a resource is or is not ready.
@@ -36,6 +40,28 @@ const (
 	ReasonDeleting runtimev1alpha1.ConditionReason = "Definition is being deleted"
 )
 
+// Reasons a composite resource is or is not ready.
+const (
+	ReasonQuotaExceeded runtimev1alpha1.ConditionReason = "Provider quota exceeded for composed resource"
+)
+
+// Reasons a composed resource is or is not synced. A provider opts in to
+// having composite resources back off when it exceeds a quota by setting the
+// Synced condition of a managed resource to False with one of these reasons.
+const (
+	ReasonComposedQuotaExceeded runtimev1alpha1.ConditionReason = "QuotaExceeded"
+)
+
+// Reasons a composite resource has selected a composition.
+const (
+	ReasonCompositionSelected runtimev1alpha1.ConditionReason = "Selected the most specific compatible composition"
+)
+
+// Reasons a composite resource is or is not synced.
+const (
+	ReasonReconcilePaused runtimev1alpha1.ConditionReason = "ReconcilePaused"
+)
+
 // Starting returns a condition that indicates a definition or publication is
 // establishing its CustomResourceDefinition and starting its controller.
 func Starting() runtimev1alpha1.Condition {
@@ -68,3 +94,41 @@ func Deleting() runtimev1alpha1.Condition {
 		Reason:             ReasonDeleting,
 	}
 }
+
+// QuotaExceeded returns a condition that indicates a composite resource is not
+// ready because the provider of one of its composed resources reported that a
+// quota was exceeded.
+func QuotaExceeded(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               runtimev1alpha1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonQuotaExceeded,
+		Message:            msg,
+	}
+}
+
+// CompositionSelected returns a condition that indicates a composite resource
+// selected a composition using its composition selector. The supplied message
+// should explain why the composition was selected.
+func CompositionSelected(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeCompositionSelected,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCompositionSelected,
+		Message:            msg,
+	}
+}
+
+// ReconcilePaused returns a condition that indicates reconciliation of a
+// composite resource is paused, and its composed resources are left as they
+// are.
+func ReconcilePaused() runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               runtimev1alpha1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReconcilePaused,
+	}
+}
@@ -36,6 +36,14 @@ type InfrastructureDefinitionSpec struct {
 	// CRDSpecTemplate is the base CRD template. The final CRD will have additional
 	// fields to the base template to accommodate Crossplane machinery.
 	CRDSpecTemplate CRDSpecTemplate `json:"crdSpecTemplate,omitempty"`
+
+	// MaxComposedResources is the maximum number of resources that may be
+	// composed by a single resource of the defined kind. It overrides the
+	// maximum Crossplane was started with, if any, and may be used to allow
+	// legitimately large compositions. Zero means there is no maximum.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxComposedResources *int64 `json:"maxComposedResources,omitempty"`
 }
 
 // A CRDSpecTemplate is a template for a v1beta1.CustomResourceDefinitionSpec.
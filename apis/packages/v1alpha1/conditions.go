@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+)
+
+// Condition types.
+const (
+	// TypeEstablished indicates whether the objects unpacked from a package
+	// have been established in the API server.
+	TypeEstablished runtimev1alpha1.ConditionType = "Established"
+
+	// TypeValidated indicates whether the objects unpacked from a package
+	// passed validation before they were established.
+	TypeValidated runtimev1alpha1.ConditionType = "Validated"
+)
+
+// Reasons the objects unpacked from a package are or are not established.
+const (
+	ReasonEstablished          runtimev1alpha1.ConditionReason = "Established"
+	ReasonObjectCountMismatch  runtimev1alpha1.ConditionReason = "ObjectCountMismatch"
+	ReasonEstablishing         runtimev1alpha1.ConditionReason = "Establishing"
+	ReasonCRDTerminating       runtimev1alpha1.ConditionReason = "CRDTerminating"
+	ReasonCRDNonStructural     runtimev1alpha1.ConditionReason = "CRDNonStructural"
+	ReasonStorageVersionChange runtimev1alpha1.ConditionReason = "CRDStorageVersionChange"
+)
+
+// Reasons the objects unpacked from a package are or are not valid.
+const (
+	ReasonValid   runtimev1alpha1.ConditionReason = "ValidPackage"
+	ReasonInvalid runtimev1alpha1.ConditionReason = "InvalidPackage"
+)
+
+// Reasons a package resource is or is not synced.
+const (
+	ReasonReconcilePaused runtimev1alpha1.ConditionReason = "ReconcilePaused"
+)
+
+// ReconcilePaused returns a condition that indicates reconciliation of a
+// package resource has been paused by its paused annotation.
+func ReconcilePaused() runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               runtimev1alpha1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReconcilePaused,
+	}
+}
+
+// Established returns a condition that indicates every object declared by a
+// package was established.
+func Established(total int) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonEstablished,
+		Message:            "Established all declared package objects: " + establishProgress(total, total),
+	}
+}
+
+// Establishing returns a condition that indicates only some of the objects
+// declared by a package have been established so far.
+func Establishing(established, total int) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonEstablishing,
+		Message:            "Established " + establishProgress(established, total),
+	}
+}
+
+// EstablishProgress returns a condition that reflects the supplied progress
+// establishing the objects declared by a package. The condition is only true
+// once every object has been established.
+func EstablishProgress(established, total int) runtimev1alpha1.Condition {
+	if established == total {
+		return Established(total)
+	}
+	return Establishing(established, total)
+}
+
+// establishProgress describes how many of the objects declared by a package
+// have been established, including as a percentage.
+func establishProgress(established, total int) string {
+	pct := 100
+	if total > 0 {
+		pct = established * 100 / total
+	}
+	return fmt.Sprintf("%d/%d objects (%d%%)", established, total, pct)
+}
+
+// ObjectCountMismatch returns a condition that indicates fewer objects were
+// established than a package declared, for example because some objects
+// already existed and were skipped.
+func ObjectCountMismatch(declared, established int) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonObjectCountMismatch,
+		Message:            "Established object count does not match declared count: " + establishProgress(established, declared),
+	}
+}
+
+// CRDTerminating returns a condition that indicates a package object could not
+// be established because the existing CRD with the supplied name is being
+// deleted.
+func CRDTerminating(name string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCRDTerminating,
+		Message:            fmt.Sprintf("CRD %s is terminating", name),
+	}
+}
+
+// CRDNonStructural returns a condition that indicates the existing CRD with
+// the supplied name has a schema that the API server reports is not
+// structural.
+func CRDNonStructural(name, msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCRDNonStructural,
+		Message:            fmt.Sprintf("CRD %s has a non-structural schema: %s", name, msg),
+	}
+}
+
+// StorageVersionChange returns a condition that indicates the existing CRD with
+// the supplied name was not updated because doing so would change its storage
+// version. Objects stored at the old version must be migrated first.
+func StorageVersionChange(name, from, to string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonStorageVersionChange,
+		Message:            fmt.Sprintf("CRD %s storage version would change from %s to %s; migrate stored objects before upgrading", name, from, to),
+	}
+}
+
+// Validated returns a condition that indicates the objects unpacked from a
+// package passed validation.
+func Validated() runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeValidated,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonValid,
+	}
+}
+
+// Invalid returns a condition that indicates the objects unpacked from a
+// package failed validation, for the supplied reasons.
+func Invalid(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeValidated,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInvalid,
+		Message:            msg,
+	}
+}
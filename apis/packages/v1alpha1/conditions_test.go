@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestEstablishProgress(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		established int
+		total       int
+		want        runtimev1alpha1.Condition
+	}{
+		"Partial": {
+			reason:      "A package whose objects are only partly established should not yet be considered established.",
+			established: 142,
+			total:       512,
+			want: runtimev1alpha1.Condition{
+				Type:    TypeEstablished,
+				Status:  corev1.ConditionFalse,
+				Reason:  ReasonEstablishing,
+				Message: "Established 142/512 objects (27%)",
+			},
+		},
+		"Complete": {
+			reason:      "A package whose objects are all established should be considered established.",
+			established: 512,
+			total:       512,
+			want: runtimev1alpha1.Condition{
+				Type:    TypeEstablished,
+				Status:  corev1.ConditionTrue,
+				Reason:  ReasonEstablished,
+				Message: "Established all declared package objects: 512/512 objects (100%)",
+			},
+		},
+		"NoObjects": {
+			reason: "A package that declares no objects should be considered established.",
+			want: runtimev1alpha1.Condition{
+				Type:    TypeEstablished,
+				Status:  corev1.ConditionTrue,
+				Reason:  ReasonEstablished,
+				Message: "Established all declared package objects: 0/0 objects (100%)",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := EstablishProgress(tc.established, tc.total)
+			if diff := cmp.Diff(tc.want, got, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nEstablishProgress(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObjectCountMismatch(t *testing.T) {
+	want := runtimev1alpha1.Condition{
+		Type:    TypeEstablished,
+		Status:  corev1.ConditionFalse,
+		Reason:  ReasonObjectCountMismatch,
+		Message: "Established object count does not match declared count: 1/2 objects (50%)",
+	}
+	got := ObjectCountMismatch(2, 1)
+	if diff := cmp.Diff(want, got, test.EquateConditions()); diff != "" {
+		t.Errorf("ObjectCountMismatch(...): -want, +got:\n%s", diff)
+	}
+}
@@ -68,7 +68,10 @@ type PackageInstallSpec struct {
 	Source string `json:"source,omitempty"`
 
 	// Package is the name of the package package that is being requested, e.g.,
-	// myapp. Either Package or CustomResourceDefinition can be specified.
+	// myapp. Either Package or CustomResourceDefinition can be specified. A
+	// Package may also be the http:// or https:// URL of a gzipped tarball
+	// containing the package's .registry directory, in which case Source is
+	// ignored and the package controller must specify its own image.
 	Package string `json:"package,omitempty"`
 
 	// CustomResourceDefinition is the full name of a CRD that is owned by the
@@ -96,6 +99,105 @@ type PackageControllerOptions struct {
 	// ServiceAccount options allow for changes to the ServiceAccount the
 	// Package Manager creates for the Package's controller
 	ServiceAccount *ServiceAccountOptions `json:"serviceAccount,omitempty"`
+
+	SchedulingOptions `json:",inline"`
+
+	ProbeOptions `json:",inline"`
+
+	ReplicaOptions `json:",inline"`
+
+	// ControllerRuntime determines how the Package's controller is run. The
+	// Package Manager runs the controller as a Deployment by default. An
+	// External controller is assumed to be run outside of the cluster, for
+	// example as a local process while it is being developed; no Deployment
+	// is created for it.
+	// +kubebuilder:validation:Enum=Deployment;External
+	ControllerRuntime ControllerRuntime `json:"controllerRuntime,omitempty"`
+
+	// PreActivationHook is a Job that is run to completion after the Package
+	// is unpacked, but before the objects it outputs are established and its
+	// controller is started. It may be used to migrate existing resources.
+	// The Package is not activated if the Job fails.
+	PreActivationHook *PreActivationHook `json:"preActivationHook,omitempty"`
+}
+
+// A PreActivationHook specifies a Job that must succeed before a Package is
+// activated. The Job runs a single container alongside the Package's install
+// job, and is deleted once the Package is activated.
+type PreActivationHook struct {
+	// Image of the container the Job runs.
+	Image string `json:"image"`
+
+	// Command of the container the Job runs. The image's entrypoint is used
+	// if it is omitted.
+	Command []string `json:"command,omitempty"`
+
+	// Args of the container the Job runs. The image's command is used if it
+	// is omitted.
+	Args []string `json:"args,omitempty"`
+
+	// Env is the environment variables of the container the Job runs.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// BackoffLimit is the number of times the Job's pod is retried before the
+	// Job is considered to have failed. Defaults to 0.
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// ProbeOptions override the probes of the containers of a Package's
+// controller. The probes specified by the Package are used when they are
+// omitted.
+type ProbeOptions struct {
+	// LivenessProbe replaces the liveness probe of the Package controller's
+	// containers.
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// ReadinessProbe replaces the readiness probe of the Package
+	// controller's containers.
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+}
+
+// ReplicaOptions configure how many replicas of a Package's controller run.
+type ReplicaOptions struct {
+	// Replicas overrides the number of replicas of the Package controller's
+	// Deployment.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// LeaderElection tells the Package controller's containers to elect a
+	// leader, such that only one replica is active at a time. It is enabled
+	// by default when the controller runs more than one replica. Containers
+	// are told to elect a leader by the LEADER_ELECTION environment variable.
+	LeaderElection *bool `json:"leaderElection,omitempty"`
+}
+
+// A ControllerRuntime determines how a Package's controller is run.
+type ControllerRuntime string
+
+// Package controller runtimes.
+const (
+	// ControllerRuntimeDeployment runs the Package's controller as a
+	// Deployment managed by the Package Manager.
+	ControllerRuntimeDeployment ControllerRuntime = "Deployment"
+
+	// ControllerRuntimeExternal assumes the Package's controller is run
+	// outside of the Package Manager's control.
+	ControllerRuntimeExternal ControllerRuntime = "External"
+)
+
+// SchedulingOptions constrain the nodes on which the pods of a Package's
+// controller may be scheduled. They are applied to the pod template of the
+// Package controller's Deployment.
+type SchedulingOptions struct {
+	// NodeSelector is merged into the node selector of the Package
+	// controller's pods.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are appended to the tolerations of the Package
+	// controller's pods.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity replaces the affinity of the Package controller's pods.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 }
 
 // PackageInstallStatus represents the observed state of a PackageInstall.
@@ -104,6 +206,22 @@ type PackageInstallStatus struct {
 
 	InstallJob    *corev1.ObjectReference `json:"installJob,omitempty"`
 	PackageRecord *corev1.ObjectReference `json:"packageRecord,omitempty"`
+
+	// EstablishedCount is the number of objects unpacked from the package
+	// that have been established.
+	EstablishedCount int32 `json:"establishedCount,omitempty"`
+
+	// TotalObjects is the number of objects unpacked from the package.
+	TotalObjects int32 `json:"totalObjects,omitempty"`
+
+	// AdoptedCount is the number of established objects that already existed
+	// and were not previously managed by the package manager.
+	AdoptedCount int32 `json:"adoptedCount,omitempty"`
+
+	// NextEstablishAttempt is the earliest time at which the package manager
+	// will try again to establish the objects unpacked from the package,
+	// after a transient failure to establish them.
+	NextEstablishAttempt *metav1.Time `json:"nextEstablishAttempt,omitempty"`
 }
 
 // Image returns the Package prefixed with a source (if available). If the
@@ -195,6 +313,17 @@ func (si *ClusterPackageInstall) SetConditions(c ...runtimev1alpha1.Condition) {
 	si.Status.SetConditions(c...)
 }
 
+// GetCondition gets the PackageInstall's Status condition of the supplied type
+func (si *PackageInstall) GetCondition(ct runtimev1alpha1.ConditionType) runtimev1alpha1.Condition {
+	return si.Status.GetCondition(ct)
+}
+
+// GetCondition gets the ClusterPackageInstall's Status condition of the
+// supplied type
+func (si *ClusterPackageInstall) GetCondition(ct runtimev1alpha1.ConditionType) runtimev1alpha1.Condition {
+	return si.Status.GetCondition(ct)
+}
+
 // GetImagePullSecrets gets the ImagePullSecrets of the ClusterPackageInstall
 // Spec
 func (si *ClusterPackageInstall) GetImagePullSecrets() []corev1.LocalObjectReference {
@@ -237,6 +366,59 @@ func (si *ClusterPackageInstall) SetImagePullPolicy(policy corev1.PullPolicy) {
 	si.Spec.ImagePullPolicy = policy
 }
 
+// GetControllerRuntime gets the ControllerRuntime of the
+// ClusterPackageInstall Spec
+func (si *ClusterPackageInstall) GetControllerRuntime() ControllerRuntime {
+	return si.Spec.ControllerRuntime
+}
+
+// GetControllerRuntime gets the ControllerRuntime of the PackageInstall Spec
+func (si *PackageInstall) GetControllerRuntime() ControllerRuntime {
+	return si.Spec.ControllerRuntime
+}
+
+// GetPreActivationHook gets the PreActivationHook of the
+// ClusterPackageInstall Spec
+func (si *ClusterPackageInstall) GetPreActivationHook() *PreActivationHook {
+	return si.Spec.PreActivationHook
+}
+
+// GetPreActivationHook gets the PreActivationHook of the PackageInstall Spec
+func (si *PackageInstall) GetPreActivationHook() *PreActivationHook {
+	return si.Spec.PreActivationHook
+}
+
+// GetReplicaOptions gets the ReplicaOptions of the ClusterPackageInstall Spec
+func (si *ClusterPackageInstall) GetReplicaOptions() ReplicaOptions {
+	return si.Spec.ReplicaOptions
+}
+
+// GetReplicaOptions gets the ReplicaOptions of the PackageInstall Spec
+func (si *PackageInstall) GetReplicaOptions() ReplicaOptions {
+	return si.Spec.ReplicaOptions
+}
+
+// GetProbeOptions gets the ProbeOptions of the ClusterPackageInstall Spec
+func (si *ClusterPackageInstall) GetProbeOptions() ProbeOptions {
+	return si.Spec.ProbeOptions
+}
+
+// GetProbeOptions gets the ProbeOptions of the PackageInstall Spec
+func (si *PackageInstall) GetProbeOptions() ProbeOptions {
+	return si.Spec.ProbeOptions
+}
+
+// GetSchedulingOptions gets the SchedulingOptions of the
+// ClusterPackageInstall Spec
+func (si *ClusterPackageInstall) GetSchedulingOptions() SchedulingOptions {
+	return si.Spec.SchedulingOptions
+}
+
+// GetSchedulingOptions gets the SchedulingOptions of the PackageInstall Spec
+func (si *PackageInstall) GetSchedulingOptions() SchedulingOptions {
+	return si.Spec.SchedulingOptions
+}
+
 // GetServiceAccountAnnotations gets the Annotations of the
 // ClusterPackageInstall Spec ServiceAccount
 func (si *ClusterPackageInstall) GetServiceAccountAnnotations() map[string]string {
@@ -313,6 +495,54 @@ func (si *PackageInstall) PackageRecord() *corev1.ObjectReference {
 	return si.Status.PackageRecord
 }
 
+// SetObjectCounts sets the ClusterPackageInstall's Status EstablishedCount and
+// TotalObjects
+func (si *ClusterPackageInstall) SetObjectCounts(established, total int) {
+	si.Status.EstablishedCount = int32(established)
+	si.Status.TotalObjects = int32(total)
+}
+
+// SetObjectCounts sets the PackageInstall's Status EstablishedCount and
+// TotalObjects
+func (si *PackageInstall) SetObjectCounts(established, total int) {
+	si.Status.EstablishedCount = int32(established)
+	si.Status.TotalObjects = int32(total)
+}
+
+// SetAdoptedCount sets the ClusterPackageInstall's Status AdoptedCount
+func (si *ClusterPackageInstall) SetAdoptedCount(adopted int) {
+	si.Status.AdoptedCount = int32(adopted)
+}
+
+// SetAdoptedCount sets the PackageInstall's Status AdoptedCount
+func (si *PackageInstall) SetAdoptedCount(adopted int) {
+	si.Status.AdoptedCount = int32(adopted)
+}
+
+// GetNextEstablishAttempt gets the ClusterPackageInstall's Status
+// NextEstablishAttempt
+func (si *ClusterPackageInstall) GetNextEstablishAttempt() *metav1.Time {
+	return si.Status.NextEstablishAttempt
+}
+
+// GetNextEstablishAttempt gets the PackageInstall's Status
+// NextEstablishAttempt
+func (si *PackageInstall) GetNextEstablishAttempt() *metav1.Time {
+	return si.Status.NextEstablishAttempt
+}
+
+// SetNextEstablishAttempt sets the ClusterPackageInstall's Status
+// NextEstablishAttempt
+func (si *ClusterPackageInstall) SetNextEstablishAttempt(t *metav1.Time) {
+	si.Status.NextEstablishAttempt = t
+}
+
+// SetNextEstablishAttempt sets the PackageInstall's Status
+// NextEstablishAttempt
+func (si *PackageInstall) SetNextEstablishAttempt(t *metav1.Time) {
+	si.Status.NextEstablishAttempt = t
+}
+
 // GroupVersionKind gets the GroupVersionKind of the PackageInstall
 func (si *PackageInstall) GroupVersionKind() schema.GroupVersionKind {
 	return PackageInstallGroupVersionKind
@@ -336,9 +566,15 @@ type PackageInstaller interface {
 	GetImagePullPolicy() corev1.PullPolicy
 	GetImagePullSecrets() []corev1.LocalObjectReference
 	GetServiceAccountAnnotations() map[string]string
+	GetSchedulingOptions() SchedulingOptions
+	GetProbeOptions() ProbeOptions
+	GetReplicaOptions() ReplicaOptions
+	GetControllerRuntime() ControllerRuntime
+	GetPreActivationHook() *PreActivationHook
 	ImageWithSource(string) (string, error)
 	InstallJob() *corev1.ObjectReference
 	PermissionScope() string
+	GetCondition(ct runtimev1alpha1.ConditionType) runtimev1alpha1.Condition
 	SetConditions(c ...runtimev1alpha1.Condition)
 	SetImagePullPolicy(corev1.PullPolicy)
 	SetImagePullSecrets([]corev1.LocalObjectReference)
@@ -346,6 +582,10 @@ type PackageInstaller interface {
 	SetSource(string)
 	SetPackageRecord(*corev1.ObjectReference)
 	SetInstallJob(*corev1.ObjectReference)
+	SetObjectCounts(established, total int)
+	SetAdoptedCount(adopted int)
+	GetNextEstablishAttempt() *metav1.Time
+	SetNextEstablishAttempt(*metav1.Time)
 	PackageRecord() *corev1.ObjectReference
 }
 
@@ -460,6 +700,33 @@ type AppMetadataSpec struct {
 
 	// +kubebuilder:validation:Enum=Cluster;Namespaced
 	PermissionScope string `json:"permissionScope,omitempty"`
+
+	// Capabilities are the optional establishment behaviors this package
+	// relies on. The objects a package outputs that rely on a capability it
+	// does not declare are not established. A package that declares no
+	// capabilities is established as though it declared them all.
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// Package capabilities.
+const (
+	// CapabilityConversionWebhooks packages output CRDs that use webhook
+	// conversion.
+	CapabilityConversionWebhooks = "conversion-webhooks"
+)
+
+// HasCapability returns true if the supplied capability is declared by this
+// package, or if this package declares no capabilities.
+func (m *AppMetadataSpec) HasCapability(c string) bool {
+	if len(m.Capabilities) == 0 {
+		return true
+	}
+	for _, d := range m.Capabilities {
+		if d == c {
+			return true
+		}
+	}
+	return false
 }
 
 // CRDList is the full list of CRDs that this package owns and depends on
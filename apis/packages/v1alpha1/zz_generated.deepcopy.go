@@ -56,6 +56,11 @@ func (in *AppMetadataSpec) DeepCopyInto(out *AppMetadataSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppMetadataSpec.
@@ -351,6 +356,14 @@ func (in *PackageControllerOptions) DeepCopyInto(out *PackageControllerOptions)
 		*out = new(ServiceAccountOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	in.SchedulingOptions.DeepCopyInto(&out.SchedulingOptions)
+	in.ProbeOptions.DeepCopyInto(&out.ProbeOptions)
+	in.ReplicaOptions.DeepCopyInto(&out.ReplicaOptions)
+	if in.PreActivationHook != nil {
+		in, out := &in.PreActivationHook, &out.PreActivationHook
+		*out = new(PreActivationHook)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageControllerOptions.
@@ -452,6 +465,10 @@ func (in *PackageInstallStatus) DeepCopyInto(out *PackageInstallStatus) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.NextEstablishAttempt != nil {
+		in, out := &in.NextEstablishAttempt, &out.NextEstablishAttempt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageInstallStatus.
@@ -578,6 +595,127 @@ func (in *PermissionsSpec) DeepCopy() *PermissionsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreActivationHook) DeepCopyInto(out *PreActivationHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreActivationHook.
+func (in *PreActivationHook) DeepCopy() *PreActivationHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PreActivationHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeOptions) DeepCopyInto(out *ProbeOptions) {
+	*out = *in
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeOptions.
+func (in *ProbeOptions) DeepCopy() *ProbeOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaOptions) DeepCopyInto(out *ReplicaOptions) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LeaderElection != nil {
+		in, out := &in.LeaderElection, &out.LeaderElection
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaOptions.
+func (in *ReplicaOptions) DeepCopy() *ReplicaOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingOptions) DeepCopyInto(out *SchedulingOptions) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingOptions.
+func (in *SchedulingOptions) DeepCopy() *SchedulingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountOptions) DeepCopyInto(out *ServiceAccountOptions) {
 	*out = *in
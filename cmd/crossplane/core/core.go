@@ -35,20 +35,24 @@ import (
 
 // Command configuration for the core Crossplane controllers.
 type Command struct {
-	Name string
-	Sync time.Duration
+	Name                     string
+	Sync                     time.Duration
+	CompositeReconcileJitter time.Duration
+	MaxComposedResources     int64
 }
 
 // FromKingpin produces the core Crossplane command from a Kingpin command.
 func FromKingpin(cmd *kingpin.CmdClause) *Command {
 	c := &Command{Name: cmd.FullCommand()}
 	cmd.Flag("sync", "Controller manager sync period duration such as 300ms, 1.5h or 2h45m").Short('s').Default("1h").DurationVar(&c.Sync)
+	cmd.Flag("composite-reconcile-jitter", "Maximum random jitter added to composite resource requeue intervals, such as 10s or 1m").Default("0s").DurationVar(&c.CompositeReconcileJitter)
+	cmd.Flag("max-composed-resources", "Maximum number of resources a composite resource may compose, unless overridden by its definition. Zero means there is no maximum.").Default("0").Int64Var(&c.MaxComposedResources)
 	return c
 }
 
 // Run core Crossplane controllers.
 func (c *Command) Run(log logging.Logger) error {
-	log.Debug("Starting", "sync-period", c.Sync.String())
+	log.Debug("Starting", "sync-period", c.Sync.String(), "composite-reconcile-jitter", c.CompositeReconcileJitter.String(), "max-composed-resources", c.MaxComposedResources)
 
 	cfg, err := ctrl.GetConfig()
 	if err != nil {
@@ -80,7 +84,7 @@ func (c *Command) Run(log logging.Logger) error {
 		return errors.Wrap(err, "Cannot setup workload controllers")
 	}
 
-	if err := apiextensions.Setup(mgr, log); err != nil {
+	if err := apiextensions.Setup(mgr, log, c.CompositeReconcileJitter, c.MaxComposedResources); err != nil {
 		return errors.Wrap(err, "Cannot setup API extension controllers")
 	}
 
@@ -26,6 +26,7 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane/cmd/crossplane/core"
+	"github.com/crossplane/crossplane/cmd/crossplane/package/fetch"
 	"github.com/crossplane/crossplane/cmd/crossplane/package/manage"
 	"github.com/crossplane/crossplane/cmd/crossplane/package/unpack"
 )
@@ -40,6 +41,7 @@ func main() {
 	c := core.FromKingpin(app.Command("core", "Start core Crossplane controllers.").Default())
 	m := manage.FromKingpin(pkg.Command("manage", "Start Crossplane Package Manager controllers"))
 	u := unpack.FromKingpin(pkg.Command("unpack", "Unpack a Package").Alias("unpackage"))
+	f := fetch.FromKingpin(pkg.Command("fetch", "Fetch the contents of a Package tarball"))
 	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 
 	// NOTE(negz): We must setup our logger after calling kingpin.MustParse in
@@ -59,6 +61,8 @@ func main() {
 		kingpin.FatalIfError(m.Run(logging.NewLogrLogger(zl.WithName("package-manager"))), "cannot run package manager")
 	case u.Name:
 		kingpin.FatalIfError(u.Run(logging.NewLogrLogger(zl.WithName("package-unpack"))), "cannot unpack package")
+	case f.Name:
+		kingpin.FatalIfError(f.Run(logging.NewLogrLogger(zl.WithName("package-fetch"))), "cannot fetch package")
 	default:
 		kingpin.FatalUsage("unknown command %s", cmd)
 	}
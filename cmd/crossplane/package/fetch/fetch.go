@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane/pkg/packages"
+)
+
+// Command configuration for fetching package tarballs.
+type Command struct {
+	Name    string
+	URL     string
+	Dir     string
+	SHA256  string
+	Timeout time.Duration
+}
+
+// FromKingpin produces a package fetch command from a Kingpin command.
+func FromKingpin(cmd *kingpin.CmdClause) *Command {
+	c := &Command{Name: cmd.FullCommand()}
+	cmd.Flag("url", "The http:// or https:// URL of the gzipped package tarball").Required().StringVar(&c.URL)
+	cmd.Flag("dir", "The absolute path of the directory into which the package contents will be unpacked").Required().StringVar(&c.Dir)
+	cmd.Flag("sha256", "The hex encoded SHA256 checksum the package tarball must have").StringVar(&c.SHA256)
+	cmd.Flag("timeout", "How long fetching the package tarball may take, such as 5m").Default("5m").DurationVar(&c.Timeout)
+	return c
+}
+
+// Run the package fetch command.
+func (c *Command) Run(log logging.Logger) error {
+	log.Debug("Fetching package", "url", c.URL, "to", c.Dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	f := &packages.HTTPTarballFetcher{Client: &http.Client{Timeout: c.Timeout}, Fs: afero.NewOsFs(), SHA256: c.SHA256}
+	return errors.Wrap(f.Fetch(ctx, c.URL, c.Dir), "failed to fetch package")
+}
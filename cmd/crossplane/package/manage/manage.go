@@ -21,7 +21,10 @@ import (
 
 	"github.com/pkg/errors"
 	"gopkg.in/alecthomas/kingpin.v2"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,6 +32,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane/apis"
 	"github.com/crossplane/crossplane/pkg/controller/packages"
+	"github.com/crossplane/crossplane/pkg/controller/packages/install"
 	"github.com/crossplane/crossplane/pkg/controller/packages/templates"
 )
 
@@ -43,6 +47,28 @@ type Command struct {
 	HostControllerNamespace   string
 	TenantKubeConfig          string
 	ForceImagePullPolicy      string
+	DefaultImagePullPolicy    string
+	HealthProbeBindAddress    string
+	LastEstablishWindow       time.Duration
+	DefaultCPURequest         string
+	DefaultMemoryRequest      string
+	DefaultCPULimit           string
+	DefaultMemoryLimit        string
+	UnpackCPURequest          string
+	UnpackMemoryRequest       string
+	UnpackCPULimit            string
+	UnpackMemoryLimit         string
+	ValidateCRDGroups         bool
+	ValidatePackages          bool
+	CRDConflictStrategy       string
+	EstablishCooldown         time.Duration
+	MergeCRDPrinterColumns    bool
+	CRDImmutableFields        []string
+	EstablishObjectTimeout    time.Duration
+	LegacyCRDs                string
+	PropagateAnnotations      []string
+	EstablishKinds            []string
+	TraceEstablishment        bool
 }
 
 // FromKingpin produces the package manager command from a Kingpin command.
@@ -56,6 +82,28 @@ func FromKingpin(cmd *kingpin.CmdClause) *Command {
 	cmd.Flag("host-controller-namespace", "The namespace on Host Cluster where install and controller jobs/deployments will be created. Setting this will activate host aware mode of Package Manager").StringVar(&c.HostControllerNamespace)
 	cmd.Flag("tenant-kubeconfig", "The absolute path of the kubeconfig file to Tenant Kubernetes instance (required for host aware mode, ignored otherwise).").ExistingFileVar(&c.TenantKubeConfig)
 	cmd.Flag("force-image-pull-policy", "All containers created by the PackageManager in service of PackageInstall and Package resources will use the specified imagePullPolicy").StringVar(&c.ForceImagePullPolicy)
+	cmd.Flag("default-image-pull-policy", "Containers created by the PackageManager in service of PackageInstall and ClusterPackageInstall resources that do not specify an imagePullPolicy will use the specified imagePullPolicy, such as IfNotPresent.").StringVar(&c.DefaultImagePullPolicy)
+	cmd.Flag("health-probe-bind-address", "The TCP address on which to serve health probes, such as :8081. Health probes are not served when omitted.").StringVar(&c.HealthProbeBindAddress)
+	cmd.Flag("last-establish-window", "Report the package manager unhealthy if it has been failing to establish the objects of a package install for longer than this duration, such as 1h. Disabled when omitted.").DurationVar(&c.LastEstablishWindow)
+	cmd.Flag("default-controller-cpu-request", "The CPU request of Package controller containers that do not specify any resource requirements, such as 100m.").StringVar(&c.DefaultCPURequest)
+	cmd.Flag("default-controller-memory-request", "The memory request of Package controller containers that do not specify any resource requirements, such as 128Mi.").StringVar(&c.DefaultMemoryRequest)
+	cmd.Flag("default-controller-cpu-limit", "The CPU limit of Package controller containers that do not specify any resource requirements, such as 500m.").StringVar(&c.DefaultCPULimit)
+	cmd.Flag("default-controller-memory-limit", "The memory limit of Package controller containers that do not specify any resource requirements, such as 512Mi.").StringVar(&c.DefaultMemoryLimit)
+	cmd.Flag("unpack-cpu-request", "The CPU request of the containers of package install jobs, such as 100m.").StringVar(&c.UnpackCPURequest)
+	cmd.Flag("unpack-memory-request", "The memory request of the containers of package install jobs, such as 128Mi.").StringVar(&c.UnpackMemoryRequest)
+	cmd.Flag("unpack-cpu-limit", "The CPU limit of the containers of package install jobs, such as 500m.").StringVar(&c.UnpackCPULimit)
+	cmd.Flag("unpack-memory-limit", "The memory limit of the containers of package install jobs, such as 512Mi.").StringVar(&c.UnpackMemoryLimit)
+	cmd.Flag("validate-crd-groups", "Reject packages whose install jobs output CRDs outside the API groups of the CRDs the package declares it owns.").Default("false").BoolVar(&c.ValidateCRDGroups)
+	cmd.Flag("validate-packages", "Reject packages whose metadata or install job output have common problems, such as duplicate CRDs, before establishing any of their objects.").Default("false").BoolVar(&c.ValidatePackages)
+	cmd.Flag("crd-conflict-strategy", "How to establish a CRD output by a package when a CRD of the same name exists but is not managed by the package manager: Adopt, Skip, or Fail. When omitted such CRDs are adopted unless they are controlled by something else.").EnumVar(&c.CRDConflictStrategy, string(install.ConflictAdopt), string(install.ConflictSkip), string(install.ConflictFail))
+	cmd.Flag("establish-cooldown", "The minimum time to wait before trying again to establish a package's objects after a transient failure, such as 30s. Establishment is retried on every reconcile when omitted.").DurationVar(&c.EstablishCooldown)
+	cmd.Flag("merge-crd-printer-columns", "Keep the additional printer columns of existing CRDs that a package's CRDs lack when updating them, rather than replacing them.").Default("false").BoolVar(&c.MergeCRDPrinterColumns)
+	cmd.Flag("crd-immutable-field", "A dot-separated path to a field of the CRDs output by packages, such as spec.preserveUnknownFields, that is set when a CRD is created but never overwritten when it is updated. May be specified multiple times.").StringsVar(&c.CRDImmutableFields)
+	cmd.Flag("establish-object-timeout", "How long establishing each object output by a package may take, such as 10s. An object that takes longer fails to establish rather than consuming the rest of the reconcile's deadline. Establishing an object is not bounded when omitted.").DurationVar(&c.EstablishObjectTimeout)
+	cmd.Flag("legacy-crds", "How to establish deprecated apiextensions.k8s.io/v1beta1 CRDs output by packages: Establish them as they are, or Convert them to apiextensions.k8s.io/v1 CRDs.").Default(string(install.LegacyCRDEstablish)).EnumVar(&c.LegacyCRDs, string(install.LegacyCRDEstablish), string(install.LegacyCRDConvert))
+	cmd.Flag("propagate-annotation", "The key of an annotation, such as example.org/commit, that is copied from PackageInstalls and ClusterPackageInstalls to the objects output by their packages when they are established. May be specified multiple times.").StringsVar(&c.PropagateAnnotations)
+	cmd.Flag("establish-kind", "Establish only the objects output by packages that are of this kind, written as kind.group, such as CustomResourceDefinition.apiextensions.k8s.io. Useful while troubleshooting. May be specified multiple times. All objects are established when omitted.").StringsVar(&c.EstablishKinds)
+	cmd.Flag("trace-establishment", "Log how long establishing the objects output by each package, and each of those objects, takes. Spans are logged at debug level.").Default("false").BoolVar(&c.TraceEstablishment)
 	return c
 }
 
@@ -72,16 +120,34 @@ func (c *Command) Run(log logging.Logger) error {
 		log.Debug("Allowing Packages to pass full deployment manifests")
 	}
 
+	dr, err := c.defaultResources()
+	if err != nil {
+		return errors.Wrap(err, "Cannot parse default controller resource requirements")
+	}
+
+	ur, err := resourceRequirements(c.UnpackCPURequest, c.UnpackMemoryRequest, c.UnpackCPULimit, c.UnpackMemoryLimit)
+	if err != nil {
+		return errors.Wrap(err, "Cannot parse package install job resource requirements")
+	}
+
 	cfg, err := getRestConfig(c.TenantKubeConfig)
 	if err != nil {
 		return errors.Wrap(err, "Cannot get config")
 	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{SyncPeriod: &c.Sync})
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{SyncPeriod: &c.Sync, HealthProbeBindAddress: c.HealthProbeBindAddress})
 	if err != nil {
 		return errors.Wrap(err, "Cannot create manager")
 	}
 
+	var tracker *install.EstablishTracker
+	if c.LastEstablishWindow > 0 {
+		tracker = install.NewEstablishTracker(c.LastEstablishWindow)
+		if err := mgr.AddHealthzCheck(install.HealthCheckLastEstablish, tracker.Check); err != nil {
+			return errors.Wrap(err, "Cannot add last establish health check")
+		}
+	}
+
 	if err := apis.AddToScheme(mgr.GetScheme()); err != nil {
 		return errors.Wrap(err, "Cannot add core Crossplane APIs to scheme")
 	}
@@ -90,7 +156,46 @@ func (c *Command) Run(log logging.Logger) error {
 		return errors.Wrap(err, "Cannot add API extensions to scheme")
 	}
 
-	if err := packages.Setup(mgr, log, c.HostControllerNamespace, c.TemplatingControllerImage, c.AllowAllAPIGroups, c.PassFullDeployment, c.ForceImagePullPolicy); err != nil {
+	opts := []install.JobCompleterOption{}
+	if c.ValidateCRDGroups {
+		opts = append(opts, install.WithCRDGroupValidation())
+	}
+	if c.ValidatePackages {
+		opts = append(opts, install.WithPackageValidation())
+	}
+	if c.CRDConflictStrategy != "" {
+		opts = append(opts, install.WithConflictStrategy(install.ConflictStrategy(c.CRDConflictStrategy)))
+	}
+	if c.EstablishCooldown > 0 {
+		opts = append(opts, install.WithEstablishCooldown(c.EstablishCooldown))
+	}
+	if c.MergeCRDPrinterColumns {
+		opts = append(opts, install.WithPrinterColumnMerge())
+	}
+	if len(c.CRDImmutableFields) > 0 {
+		opts = append(opts, install.WithImmutableFields(map[schema.GroupKind][]string{
+			apiextensionsv1beta1.Kind("CustomResourceDefinition"): c.CRDImmutableFields,
+		}))
+	}
+	if c.EstablishObjectTimeout > 0 {
+		opts = append(opts, install.WithPerObjectTimeout(c.EstablishObjectTimeout))
+	}
+	opts = append(opts, install.WithLegacyCRDPolicy(install.LegacyCRDPolicy(c.LegacyCRDs)))
+	if len(c.PropagateAnnotations) > 0 {
+		opts = append(opts, install.WithAnnotationPropagation(c.PropagateAnnotations...))
+	}
+	if len(c.EstablishKinds) > 0 {
+		gks := make([]schema.GroupKind, len(c.EstablishKinds))
+		for i, k := range c.EstablishKinds {
+			gks[i] = schema.ParseGroupKind(k)
+		}
+		opts = append(opts, install.WithEstablishFilter(gks...))
+	}
+	if c.TraceEstablishment {
+		opts = append(opts, install.WithTracer(install.NewLogTracer(log)))
+	}
+
+	if err := packages.Setup(mgr, log, c.HostControllerNamespace, c.TemplatingControllerImage, c.AllowAllAPIGroups, c.PassFullDeployment, c.ForceImagePullPolicy, c.DefaultImagePullPolicy, dr, ur, tracker, opts...); err != nil {
 		return errors.Wrap(err, "Cannot add packages controllers to manager")
 	}
 
@@ -107,6 +212,41 @@ func (c *Command) Run(log logging.Logger) error {
 	return errors.Wrap(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
 
+// defaultResources returns the resource requirements of Package controller
+// containers that do not specify their own.
+func (c *Command) defaultResources() (corev1.ResourceRequirements, error) {
+	return resourceRequirements(c.DefaultCPURequest, c.DefaultMemoryRequest, c.DefaultCPULimit, c.DefaultMemoryLimit)
+}
+
+// resourceRequirements returns the resource requirements with the supplied
+// requests and limits. Empty quantities are omitted.
+func resourceRequirements(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) (corev1.ResourceRequirements, error) {
+	rr := corev1.ResourceRequirements{}
+	for _, q := range []struct {
+		list  *corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{list: &rr.Requests, name: corev1.ResourceCPU, value: cpuRequest},
+		{list: &rr.Requests, name: corev1.ResourceMemory, value: memoryRequest},
+		{list: &rr.Limits, name: corev1.ResourceCPU, value: cpuLimit},
+		{list: &rr.Limits, name: corev1.ResourceMemory, value: memoryLimit},
+	} {
+		if q.value == "" {
+			continue
+		}
+		v, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return corev1.ResourceRequirements{}, errors.Wrapf(err, "cannot parse %s quantity %q", q.name, q.value)
+		}
+		if *q.list == nil {
+			*q.list = corev1.ResourceList{}
+		}
+		(*q.list)[q.name] = v
+	}
+	return rr, nil
+}
+
 func getRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	if kubeconfigPath == "" {
 		return ctrl.GetConfig()
@@ -17,6 +17,8 @@ limitations under the License.
 package apiextensions
 
 import (
+	"time"
+
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -25,15 +27,13 @@ import (
 	"github.com/crossplane/crossplane/pkg/controller/apiextensions/publication"
 )
 
-// Setup workload controllers.
-func Setup(mgr ctrl.Manager, l logging.Logger) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger) error{
-		definition.Setup,
-		publication.Setup,
-	} {
-		if err := setup(mgr, l); err != nil {
-			return err
-		}
+// Setup workload controllers. The requeue intervals of composite resources are
+// jittered by up to the supplied duration, and each composite resource may
+// compose at most the supplied number of resources unless its definition says
+// otherwise. Zero means there is no maximum.
+func Setup(mgr ctrl.Manager, l logging.Logger, compositeJitter time.Duration, maxComposed int64) error {
+	if err := definition.Setup(mgr, l, compositeJitter, maxComposed); err != nil {
+		return err
 	}
-	return nil
+	return publication.Setup(mgr, l)
 }
@@ -18,6 +18,9 @@ package composite
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -82,6 +85,36 @@ func (a *APIFilteredSecretPublisher) UnpublishConnection(_ context.Context, _ re
 	return nil
 }
 
+// FilterConnectionDetails returns the supplied connection details, less any
+// keys that the supplied filter does not allow. All keys are allowed by a nil
+// filter, or by a filter with an empty allow list. Denied keys take precedence
+// over allowed keys.
+func FilterConnectionDetails(conn managed.ConnectionDetails, f *v1alpha1.ConnectionDetailsFilter) managed.ConnectionDetails {
+	if f == nil {
+		return conn
+	}
+	allow := map[string]bool{}
+	for _, key := range f.Allow {
+		allow[key] = true
+	}
+	deny := map[string]bool{}
+	for _, key := range f.Deny {
+		deny[key] = true
+	}
+
+	out := managed.ConnectionDetails{}
+	for key, val := range conn {
+		if len(allow) > 0 && !allow[key] {
+			continue
+		}
+		if deny[key] {
+			continue
+		}
+		out[key] = val
+	}
+	return out
+}
+
 // NewAPISelectorResolver returns a SelectorResolver for composite resource.
 func NewAPISelectorResolver(c client.Client) *APISelectorResolver {
 	return &APISelectorResolver{client: c}
@@ -93,7 +126,12 @@ type APISelectorResolver struct {
 	client client.Client
 }
 
-// ResolveSelector resolves selector to a reference if it doesn't exist.
+// ResolveSelector resolves selector to a reference if it doesn't exist. When
+// several compatible compositions match the selector the most specific one is
+// selected; i.e. the one with the fewest labels that the selector does not
+// match. Compositions that are equally specific are selected in lexical order
+// of their names. The selection and the reason for it are recorded in the
+// composite resource's CompositionSelected condition.
 func (r *APISelectorResolver) ResolveSelector(ctx context.Context, cp resource.Composite) error {
 	// TODO(muvaf): need to block the deletion of composition via finalizer once
 	// it's selected since it's integral to this resource.
@@ -113,16 +151,71 @@ func (r *APISelectorResolver) ResolveSelector(ctx context.Context, cp resource.C
 		return errors.Wrap(err, errListCompositions)
 	}
 	apiVersion, kind := cp.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	for _, comp := range list.Items {
+	compatible := make([]*v1alpha1.Composition, 0, len(list.Items))
+	var selected *v1alpha1.Composition
+	for i := range list.Items {
+		comp := &list.Items[i]
 		if comp.Spec.From.APIVersion != apiVersion || comp.Spec.From.Kind != kind {
 			continue
 		}
+		compatible = append(compatible, comp)
+		if selected == nil || moreSpecific(comp, selected, labels) {
+			selected = comp
+		}
+	}
+	if selected == nil {
+		return errors.New(errNoCompatibleComposition)
+	}
+
+	cp.SetCompositionReference(meta.ReferenceTo(selected.DeepCopy(), v1alpha1.CompositionGroupVersionKind))
+	if err := r.client.Update(ctx, cp); err != nil {
+		return errors.Wrap(err, errUpdateComposite)
+	}
+
+	// We set the condition after the update, which would otherwise reset
+	// it. It is persisted along with the rest of the status.
+	cp.SetConditions(v1alpha1.CompositionSelected(selection(selected, compatible, labels)))
+	return nil
+}
+
+// selection explains why the supplied composition was selected from the
+// supplied compatible compositions, given the supplied selector labels.
+func selection(selected *v1alpha1.Composition, compatible []*v1alpha1.Composition, labels map[string]string) string {
+	n := unselected(selected.GetLabels(), labels)
+	msg := fmt.Sprintf("Selected composition %s from %d compatible composition(s); it has %d label(s) the selector does not match", selected.GetName(), len(compatible), n)
+
+	tied := make([]string, 0)
+	for _, comp := range compatible {
+		if comp != selected && unselected(comp.GetLabels(), labels) == n {
+			tied = append(tied, comp.GetName())
+		}
+	}
+	if len(tied) == 0 {
+		return msg
+	}
+	sort.Strings(tied)
+	return msg + fmt.Sprintf(". Equally specific composition(s) %s sort after it by name", strings.Join(tied, ", "))
+}
 
-		cp.SetCompositionReference(meta.ReferenceTo(comp.DeepCopy(), v1alpha1.CompositionGroupVersionKind))
+// moreSpecific returns true if composition a is a more specific match for the
+// supplied selector labels than composition b.
+func moreSpecific(a, b *v1alpha1.Composition, selected map[string]string) bool {
+	ua, ub := unselected(a.GetLabels(), selected), unselected(b.GetLabels(), selected)
+	if ua != ub {
+		return ua < ub
+	}
+	return a.GetName() < b.GetName()
+}
 
-		return errors.Wrap(r.client.Update(ctx, cp), errUpdateComposite)
+// unselected returns the number of the supplied labels that are not selected.
+func unselected(labels, selected map[string]string) int {
+	n := 0
+	for k, v := range labels {
+		if sv, ok := selected[k]; !ok || sv != v {
+			n++
+		}
 	}
-	return errors.New(errNoCompatibleComposition)
+	return n
 }
 
 // NewAPIConfigurator returns a Configurator that configures a
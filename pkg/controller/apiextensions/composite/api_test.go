@@ -104,6 +104,50 @@ func TestPublishConnection(t *testing.T) {
 	}
 }
 
+func TestFilterConnectionDetails(t *testing.T) {
+	conn := managed.ConnectionDetails{"username": {41}, "password": {42}, "endpoint": {43}}
+
+	cases := map[string]struct {
+		reason string
+		filter *v1alpha1.ConnectionDetailsFilter
+		want   managed.ConnectionDetails
+	}{
+		"NoFilter": {
+			reason: "All connection details should be propagated when there is no filter",
+			want:   conn,
+		},
+		"EmptyFilter": {
+			reason: "All connection details should be propagated when the filter is empty",
+			filter: &v1alpha1.ConnectionDetailsFilter{},
+			want:   conn,
+		},
+		"Allow": {
+			reason: "Only allowed connection details should be propagated",
+			filter: &v1alpha1.ConnectionDetailsFilter{Allow: []string{"endpoint", "port"}},
+			want:   managed.ConnectionDetails{"endpoint": {43}},
+		},
+		"Deny": {
+			reason: "Denied connection details should not be propagated",
+			filter: &v1alpha1.ConnectionDetailsFilter{Deny: []string{"password"}},
+			want:   managed.ConnectionDetails{"username": {41}, "endpoint": {43}},
+		},
+		"AllowAndDeny": {
+			reason: "Denied connection details should not be propagated even if they are allowed",
+			filter: &v1alpha1.ConnectionDetailsFilter{Allow: []string{"username", "password"}, Deny: []string{"password"}},
+			want:   managed.ConnectionDetails{"username": {41}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FilterConnectionDetails(conn, tc.filter)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFilterConnectionDetails(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestConfigure(t *testing.T) {
 	cs := fake.ConnectionSecretWriterTo{Ref: &runtimev1alpha1.SecretReference{
 		Name:      "foo",
@@ -293,6 +337,52 @@ func TestResolveSelector(t *testing.T) {
 				cp: &fake.Composite{
 					CompositionReferencer: fake.CompositionReferencer{Ref: meta.ReferenceTo(comp, v1alpha1.CompositionGroupVersionKind)},
 					CompositionSelector:   fake.CompositionSelector{Sel: sel},
+					ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{
+						v1alpha1.CompositionSelected("Selected composition foo from 1 compatible composition(s); it has 0 label(s) the selector does not match"),
+					}},
+				},
+			},
+		},
+		"SelectedTheMostSpecificOne": {
+			reason: "Should select the compatible composition with the fewest unselected labels, then the first by name",
+			args: args{
+				kube: &test.MockClient{
+					MockUpdate: test.NewMockUpdateFn(nil),
+					MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+						withLabels := func(name string, l map[string]string) v1alpha1.Composition {
+							c := comp.DeepCopy()
+							c.SetName(name)
+							c.SetLabels(l)
+							return *c
+						}
+						compList := &v1alpha1.CompositionList{
+							Items: []v1alpha1.Composition{
+								withLabels("a-general", map[string]string{"select": "me", "region": "us", "tier": "gold"}),
+								withLabels("c-specific", map[string]string{"select": "me", "region": "us"}),
+								withLabels("b-specific", map[string]string{"select": "me", "tier": "gold"}),
+							},
+						}
+						if list, ok := obj.(*v1alpha1.CompositionList); ok {
+							compList.DeepCopyInto(list)
+							return nil
+						}
+						t.Errorf("wrong query")
+						return nil
+					}},
+				cp: &fake.Composite{
+					CompositionSelector: fake.CompositionSelector{Sel: sel},
+				},
+			},
+			want: want{
+				cp: &fake.Composite{
+					CompositionReferencer: fake.CompositionReferencer{Ref: meta.ReferenceTo(&v1alpha1.Composition{
+						ObjectMeta: metav1.ObjectMeta{Name: "b-specific", Namespace: comp.GetNamespace()},
+					}, v1alpha1.CompositionGroupVersionKind)},
+					CompositionSelector: fake.CompositionSelector{Sel: sel},
+					ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{
+						v1alpha1.CompositionSelected("Selected composition b-specific from 3 compatible composition(s); it has 1 label(s) the selector does not match. " +
+							"Equally specific composition(s) c-specific sort after it by name"),
+					}},
 				},
 			},
 		},
@@ -304,7 +394,7 @@ func TestResolveSelector(t *testing.T) {
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nResolveSelector(...): -want, +got:\n%s", tc.reason, diff)
 			}
-			if diff := cmp.Diff(tc.want.cp, tc.args.cp); diff != "" {
+			if diff := cmp.Diff(tc.want.cp, tc.args.cp, test.EquateConditions()); diff != "" {
 				t.Errorf("\n%s\nResolveSelector(...): -want, +got:\n%s", tc.reason, diff)
 			}
 		})
@@ -61,6 +61,23 @@ func (*DefaultConfigurator) Configure(cp resource.Composite, cd resource.Compose
 	// restore the existing name, if any. We also set generate name in case we
 	// haven't yet named this composed resource.
 	cd.SetGenerateName(cp.GetName() + "-")
+	// We never generate the name of an observe only resource, so it must be
+	// named by its template.
+	if t.ObserveOnly && name == "" {
+		name = cd.GetName()
+	}
+	// A composed resource with a name template is named deterministically
+	// rather than by the API server. Its name is rendered only once; the
+	// template is not rendered again if the composite resource changes. The
+	// Composer won't take over an existing object with the rendered name
+	// unless the composite resource already controls it.
+	if t.NameTemplate != nil && name == "" {
+		n, err := RenderName(cp, *t.NameTemplate)
+		if err != nil {
+			return err
+		}
+		name = n
+	}
 	cd.SetName(name)
 	return nil
 }
@@ -35,6 +35,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
@@ -46,6 +47,15 @@ func TestConfigure(t *testing.T) {
 
 	tmpl, _ := json.Marshal(&fake.Managed{})
 
+	// Name templates are rendered using the fields of the composite resource,
+	// so they need a composite resource that is converted as the API server
+	// would return it.
+	named := func(name string) resource.Composite {
+		cp := composite.New()
+		cp.SetName(name)
+		return cp
+	}
+
 	type args struct {
 		cp resource.Composite
 		cd resource.Composed
@@ -82,6 +92,49 @@ func TestConfigure(t *testing.T) {
 				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd", GenerateName: "cp-"}},
 			},
 		},
+		"ObserveOnlyNamedByTemplate": {
+			reason: "An observe only composed resource without a name should be named by its template",
+			args: args{
+				cp: &fake.Composite{ObjectMeta: metav1.ObjectMeta{Name: "cp"}},
+				cd: &fake.Composed{},
+				t: v1alpha1.ComposedTemplate{
+					// fake.Composed inlines its ObjectMeta when marshalled.
+					Base:        runtime.RawExtension{Raw: []byte(`{"name":"external"}`)},
+					ObserveOnly: true,
+				},
+			},
+			want: want{
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "external", GenerateName: "cp-"}},
+			},
+		},
+		"NamedByNameTemplate": {
+			reason: "A composed resource without a name should be named by its name template",
+			args: args{
+				cp: named("cp"),
+				cd: &fake.Composed{},
+				t: v1alpha1.ComposedTemplate{
+					Base:         runtime.RawExtension{Raw: tmpl},
+					NameTemplate: pointer.StringPtr("{{ .metadata.name }}-bucket"),
+				},
+			},
+			want: want{
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cp-bucket", GenerateName: "cp-"}},
+			},
+		},
+		"NameTemplateNotRenderedAgain": {
+			reason: "A composed resource that is already named should not be renamed by its name template",
+			args: args{
+				cp: named("cp"),
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd"}},
+				t: v1alpha1.ComposedTemplate{
+					Base:         runtime.RawExtension{Raw: tmpl},
+					NameTemplate: pointer.StringPtr("{{ .metadata.name }}-bucket"),
+				},
+			},
+			want: want{
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd", GenerateName: "cp-"}},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
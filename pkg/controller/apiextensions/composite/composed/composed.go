@@ -19,14 +19,20 @@ package composed
 import (
 	"context"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	ucomposed "github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
 
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
 )
@@ -37,6 +43,12 @@ const (
 	errFetchSecret = "cannot fetch connection secret"
 	errOverlay     = "cannot apply overlay"
 	errConfigure   = "cannot configure composed resource"
+	errGetComposed = "cannot get composed resource"
+	errConvert     = "cannot convert composed resource to unstructured"
+	errReadiness   = "cannot check whether composed resource is ready"
+
+	errObserveNoName      = "observe only composed resource has no name"
+	errFmtObserveNotFound = "observe only composed resource %q does not exist"
 )
 
 // Configurator is used to configure the Composed resource.
@@ -59,6 +71,18 @@ type Observation struct {
 	Ref               corev1.ObjectReference
 	ConnectionDetails managed.ConnectionDetails
 	Ready             bool
+	Synced            runtimev1alpha1.Condition
+}
+
+// IsQuotaExceeded returns true if the supplied Synced condition indicates that
+// a composed resource could not be reconciled because its provider reported
+// that a quota or limit was exceeded. Error messages vary between cloud APIs,
+// so only providers that explicitly report the QuotaExceeded reason are
+// considered to have exceeded a quota.
+func IsQuotaExceeded(c runtimev1alpha1.Condition) bool {
+	return c.Type == runtimev1alpha1.TypeSynced &&
+		c.Status == corev1.ConditionFalse &&
+		c.Reason == v1alpha1.ReasonComposedQuotaExceeded
 }
 
 // WithClientApplicator returns a ComposerOption that changes the ClientApplicator of
@@ -135,21 +159,35 @@ type Composer struct {
 	composed
 }
 
-// Compose the supplied Composed resource into the supplied Composite resource
-// using the supplied CompositeTemplate.
-func (r *Composer) Compose(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (Observation, error) {
-
+// Render the supplied Composed resource as the supplied Composite resource
+// would compose it using the supplied CompositeTemplate. Nothing is read from
+// or written to the API server.
+func (r *Composer) Render(cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) error {
 	// Doing the configuration only once or continuously is subject to discussion
 	// in https://github.com/crossplane/crossplane/issues/1481
 	// Until it's resolved, it's done in every reconcile.
 	if err := r.composed.Configure(cp, cd, t); err != nil {
-		return Observation{}, errors.Wrap(err, errConfigure)
+		return errors.Wrap(err, errConfigure)
 	}
 
 	// Overlay is applied to the Composed resource in all cases so that we can
 	// keep Composed resource up-to-date with the changes in Composite resource.
 	if err := r.composed.Overlay(cp, cd, t); err != nil {
-		return Observation{}, errors.Wrap(err, errOverlay)
+		return errors.Wrap(err, errOverlay)
+	}
+	return nil
+}
+
+// Compose the supplied Composed resource into the supplied Composite resource
+// using the supplied CompositeTemplate.
+func (r *Composer) Compose(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (Observation, error) {
+
+	if err := r.Render(cp, cd, t); err != nil {
+		return Observation{}, err
+	}
+
+	if t.ObserveOnly {
+		return r.observe(ctx, cd, t)
 	}
 
 	// Connection details are fetched in all cases in a best-effort mode, i.e.
@@ -167,14 +205,121 @@ func (r *Composer) Compose(ctx context.Context, cp resource.Composite, cd resour
 
 	// Apply should be the last operation of this function so that we can return
 	// the reference to be stored in the Composite resource immediately.
-	if err := r.client.Apply(ctx, cd, resource.MustBeControllableBy(cp.GetUID())); err != nil {
+	ao := []resource.ApplyOption{resource.MustBeControllableBy(cp.GetUID())}
+	if t.NameTemplate != nil {
+		// A templated name may be that of an existing object we didn't
+		// create, which we must not take over.
+		ao = []resource.ApplyOption{MustBeControlledBy(cp.GetUID())}
+	}
+	if len(t.IgnoreFields) > 0 {
+		ao = append(ao, IgnoreFields(t.IgnoreFields...))
+	}
+	if err := r.client.Apply(ctx, cd, ao...); err != nil {
 		return Observation{}, errors.Wrap(err, errApply)
 	}
 
+	ready, err := IsReady(cd, t)
+	if err != nil {
+		return Observation{}, errors.Wrap(err, errReadiness)
+	}
+
 	obs := Observation{
 		Ref:               *meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind()),
-		Ready:             resource.IsConditionTrue(cd.GetCondition(runtimev1alpha1.TypeReady)),
+		Ready:             ready,
+		Synced:            cd.GetCondition(runtimev1alpha1.TypeSynced),
 		ConnectionDetails: conn,
 	}
 	return obs, nil
 }
+
+// observe the supplied observe only Composed resource. It is read, but never
+// written to.
+func (r *Composer) observe(ctx context.Context, cd resource.Composed, t v1alpha1.ComposedTemplate) (Observation, error) {
+	if cd.GetName() == "" {
+		return Observation{}, errors.New(errObserveNoName)
+	}
+
+	nn := types.NamespacedName{Namespace: cd.GetNamespace(), Name: cd.GetName()}
+	if err := r.client.Get(ctx, nn, cd); err != nil {
+		if kerrors.IsNotFound(err) {
+			return Observation{}, errors.Errorf(errFmtObserveNotFound, cd.GetName())
+		}
+		return Observation{}, errors.Wrap(err, errGetComposed)
+	}
+
+	conn, err := r.connection.Fetch(ctx, cd, t)
+	if err != nil {
+		return Observation{}, errors.Wrap(err, errFetchSecret)
+	}
+
+	ready, err := IsReady(cd, t)
+	if err != nil {
+		return Observation{}, errors.Wrap(err, errReadiness)
+	}
+
+	obs := Observation{
+		Ref:               *meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind()),
+		Ready:             ready,
+		Synced:            cd.GetCondition(runtimev1alpha1.TypeSynced),
+		ConnectionDetails: conn,
+	}
+	return obs, nil
+}
+
+// Diff returns a human readable diff between the desired state of the supplied
+// Composed resource, as rendered from the supplied ComposedTemplate, and its
+// observed state in the API server. Only fields that are rendered from the
+// template are considered, so status and server managed metadata don't count
+// as differences. The diff is empty when the composed resource is in sync.
+func (r *Composer) Diff(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (string, error) {
+	c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cd)
+	if err != nil {
+		return "", errors.Wrap(err, errConvert)
+	}
+
+	observed := &unstructured.Unstructured{}
+	observed.SetGroupVersionKind(cd.GetObjectKind().GroupVersionKind())
+	if cd.GetName() != "" {
+		nn := types.NamespacedName{Namespace: cd.GetNamespace(), Name: cd.GetName()}
+		if err := r.client.Get(ctx, nn, observed); resource.IgnoreNotFound(err) != nil {
+			return "", errors.Wrap(err, errGetComposed)
+		}
+	}
+
+	// We render the desired state exactly as Compose would, except that we
+	// don't apply it.
+	desired := &ucomposed.Unstructured{Unstructured: unstructured.Unstructured{Object: runtime.DeepCopyJSON(c)}}
+	if err := r.Render(cp, desired, t); err != nil {
+		return "", err
+	}
+
+	// Ignored fields are not applied, so they can't be out of sync.
+	d := desired.UnstructuredContent()
+	for _, p := range t.IgnoreFields {
+		if err := deleteField(d, p); err != nil {
+			return "", err
+		}
+	}
+	o := observed.UnstructuredContent()
+	return cmp.Diff(project(d, o), d), nil
+}
+
+// project returns the subset of the observed object that contains only the
+// fields that are set in the desired object.
+func project(desired, observed map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(desired))
+	for k, dv := range desired {
+		ov, ok := observed[k]
+		if !ok {
+			continue
+		}
+		dm, dok := dv.(map[string]interface{})
+		om, ook := ov.(map[string]interface{})
+		if dok && ook {
+			out[k] = project(dm, om)
+			continue
+		}
+		out[k] = ov
+	}
+	return out
+}
@@ -22,14 +22,21 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	ucomposed "github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
@@ -149,6 +156,63 @@ func TestCompose(t *testing.T) {
 				err: errors.Wrap(errBoom, errApply),
 			},
 		},
+		"ObserveOnlyNoName": {
+			reason: "An observe only composed resource that has no name cannot be observed",
+			args: args{
+				composer: NewComposer(&test.MockClient{},
+					WithConfigurator(NopConfigure),
+					WithOverlayApplicator(NopOverlay)),
+				cd: &fake.Composed{},
+				t:  v1alpha1.ComposedTemplate{ObserveOnly: true},
+			},
+			want: want{
+				err: errors.New(errObserveNoName),
+			},
+		},
+		"ObserveOnlyNotFound": {
+			reason: "An observe only composed resource that does not exist should not be created",
+			args: args{
+				composer: NewComposer(&test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "composed")),
+				},
+					WithConfigurator(NopConfigure),
+					WithOverlayApplicator(NopOverlay)),
+				cd: cd.DeepCopyObject().(*fake.Composed),
+				t:  v1alpha1.ComposedTemplate{ObserveOnly: true},
+			},
+			want: want{
+				err: errors.Errorf(errFmtObserveNotFound, "composed"),
+			},
+		},
+		"ObserveOnlySuccess": {
+			reason: "An observe only composed resource should be observed without being applied or controlled",
+			args: args{
+				composer: NewComposer(&test.MockClient{MockGet: test.NewMockGetFn(nil)},
+					WithConfigurator(NopConfigure),
+					WithOverlayApplicator(NopOverlay),
+					WithConnectionDetailFetcher(FetchFn(func(_ context.Context, _ resource.Composed, _ v1alpha1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return conn, nil
+					})),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ runtime.Object, _ ...resource.ApplyOption) error {
+							return errors.New("observe only composed resources should not be applied")
+						}),
+					})),
+				cd: cd.DeepCopyObject().(*fake.Composed),
+				cp: &fake.Composite{},
+				t:  v1alpha1.ComposedTemplate{ObserveOnly: true},
+			},
+			want: want{
+				obs: Observation{
+					Ref:               *meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind()),
+					ConnectionDetails: conn,
+					Ready:             true,
+					Synced:            cd.GetCondition(runtimev1alpha1.TypeSynced),
+				},
+				cd: cd,
+			},
+		},
 		"Success": {
 			reason: "Observation should include the right information",
 			args: args{
@@ -172,6 +236,7 @@ func TestCompose(t *testing.T) {
 					Ref:               *meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind()),
 					ConnectionDetails: conn,
 					Ready:             true,
+					Synced:            cd.GetCondition(runtimev1alpha1.TypeSynced),
 				},
 				cd: boundCD,
 			},
@@ -191,3 +256,243 @@ func TestCompose(t *testing.T) {
 	}
 
 }
+
+func TestIsQuotaExceeded(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		c      runtimev1alpha1.Condition
+		want   bool
+	}{
+		"Synced": {
+			reason: "A successfully synced resource has not exceeded its quota",
+			c:      runtimev1alpha1.ReconcileSuccess(),
+			want:   false,
+		},
+		"OtherError": {
+			reason: "A reconcile error without the QuotaExceeded reason is not a quota error",
+			c:      runtimev1alpha1.ReconcileError(errors.New("boom")),
+			want:   false,
+		},
+		"QuotaMentioned": {
+			reason: "A reconcile error that merely mentions quota is not a quota error unless the provider says so",
+			c:      runtimev1alpha1.ReconcileError(errors.New("cannot update quota project setting")),
+			want:   false,
+		},
+		"QuotaExceeded": {
+			reason: "A Synced condition with the QuotaExceeded reason is a quota error",
+			c: runtimev1alpha1.Condition{
+				Type:    runtimev1alpha1.TypeSynced,
+				Status:  corev1.ConditionFalse,
+				Reason:  v1alpha1.ReasonComposedQuotaExceeded,
+				Message: "googleapi: Error 403: Quota 'CPUS' exceeded. Limit: 24.0 in region us-central1., quotaExceeded",
+			},
+			want: true,
+		},
+		"QuotaNoLongerExceeded": {
+			reason: "A True Synced condition is not a quota error, whatever its reason",
+			c: runtimev1alpha1.Condition{
+				Type:   runtimev1alpha1.TypeSynced,
+				Status: corev1.ConditionTrue,
+				Reason: v1alpha1.ReasonComposedQuotaExceeded,
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsQuotaExceeded(tc.c)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsQuotaExceeded(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cp := &fake.Composite{ObjectMeta: metav1.ObjectMeta{Name: "composite"}}
+	tmpl := v1alpha1.ComposedTemplate{
+		Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Composed","spec":{"size":"large","region":"us-west"}}`)},
+	}
+
+	// observed returns a MockGet that returns a composed resource with the
+	// supplied spec, server managed metadata, and status.
+	observed := func(spec map[string]interface{}) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			u := obj.(*unstructured.Unstructured)
+			u.Object = map[string]interface{}{
+				"apiVersion": "example.org/v1",
+				"kind":       "Composed",
+				"metadata": map[string]interface{}{
+					"name":            "composed",
+					"generateName":    "composite-",
+					"resourceVersion": "42",
+					"uid":             "cool-uid",
+				},
+				"spec":   spec,
+				"status": map[string]interface{}{"atProvider": map[string]interface{}{"id": "cool-id"}},
+			}
+			return nil
+		}
+	}
+
+	type want struct {
+		empty bool
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		want
+	}{
+		"GetFailed": {
+			reason: "Failure to get the observed composed resource should return error",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			want: want{
+				empty: true,
+				err:   errors.Wrap(errBoom, errGetComposed),
+			},
+		},
+		"InSync": {
+			reason: "The diff should be empty when the observed composed resource matches the desired state",
+			kube:   &test.MockClient{MockGet: observed(map[string]interface{}{"region": "us-west", "size": "large"})},
+			want: want{
+				empty: true,
+			},
+		},
+		"OutOfSync": {
+			reason: "The diff should not be empty when the observed composed resource differs from the desired state",
+			kube:   &test.MockClient{MockGet: observed(map[string]interface{}{"region": "us-west", "size": "small"})},
+			want: want{
+				empty: false,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewComposer(tc.kube, WithOverlayApplicator(NopOverlay))
+			cd := ucomposed.New(ucomposed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Composed", Name: "composed"}))
+
+			diff, err := c.Diff(context.Background(), cp, cd, tmpl)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if got := diff == ""; got != tc.empty {
+				t.Errorf("\n%s\nDiff(...): want empty diff %t, got:\n%s", tc.reason, tc.empty, diff)
+			}
+		})
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	ready := func() resource.Composed {
+		cd := ucomposed.New()
+		cd.SetConditions(runtimev1alpha1.Available())
+		return cd
+	}
+	withField := func(path string, v interface{}) resource.Composed {
+		cd := ucomposed.New()
+		if err := fieldpath.Pave(cd.Object).SetValue(path, v); err != nil {
+			t.Fatal(err)
+		}
+		return cd
+	}
+
+	type args struct {
+		cd resource.Composed
+		rc *v1alpha1.ReadinessCheck
+	}
+	type want struct {
+		ready bool
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DefaultReady": {
+			reason: "A composed resource with no readiness check should be ready when its Ready condition is True",
+			args:   args{cd: ready()},
+			want:   want{ready: true},
+		},
+		"DefaultNotReady": {
+			reason: "A composed resource with no readiness check should not be ready when it has no Ready condition",
+			args:   args{cd: ucomposed.New()},
+			want:   want{ready: false},
+		},
+		"MatchCustomCondition": {
+			reason: "A composed resource should not be ready unless the specified condition is True",
+			args: args{
+				cd: ready(),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckMatchCondition, ConditionType: runtimev1alpha1.TypeSynced},
+			},
+			want: want{ready: false},
+		},
+		"NonEmptyField": {
+			reason: "A composed resource should be ready when the specified field is not empty",
+			args: args{
+				cd: withField("status.atProvider.endpoint", "example.org"),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckNonEmpty, FieldPath: "status.atProvider.endpoint"},
+			},
+			want: want{ready: true},
+		},
+		"EmptyField": {
+			reason: "A composed resource should not be ready when the specified field is empty",
+			args: args{
+				cd: withField("status.atProvider.endpoint", ""),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckNonEmpty, FieldPath: "status.atProvider.endpoint"},
+			},
+			want: want{ready: false},
+		},
+		"MissingField": {
+			reason: "A composed resource should not be ready when the specified field does not exist",
+			args: args{
+				cd: ucomposed.New(),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckNonEmpty, FieldPath: "status.atProvider.endpoint"},
+			},
+			want: want{ready: false},
+		},
+		"NonEmptyWithoutFieldPath": {
+			reason: "A NonEmpty readiness check without a field path should return an error",
+			args: args{
+				cd: ucomposed.New(),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckNonEmpty},
+			},
+			want: want{err: errors.New(errNoReadinessFieldPath)},
+		},
+		"None": {
+			reason: "A composed resource should always be ready when its readiness check is None",
+			args: args{
+				cd: ucomposed.New(),
+				rc: &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckNone},
+			},
+			want: want{ready: true},
+		},
+		"UnknownType": {
+			reason: "An unknown readiness check type should return an error",
+			args: args{
+				cd: ucomposed.New(),
+				rc: &v1alpha1.ReadinessCheck{Type: "Cool"},
+			},
+			want: want{err: errors.Errorf(errFmtReadinessCheckType, "Cool")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := IsReady(tc.args.cd, v1alpha1.ComposedTemplate{ReadinessCheck: tc.args.rc})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nIsReady(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ready, got); diff != "" {
+				t.Errorf("\n%s\nIsReady(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
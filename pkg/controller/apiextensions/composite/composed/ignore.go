@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composed
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings
+const (
+	errNotUnstructured    = "cannot ignore fields of a resource that is not unstructured"
+	errFmtIgnoreFieldPath = "cannot parse ignored field path %q"
+	errFmtIgnoreArray     = "cannot ignore field path %q: fields within arrays cannot be ignored"
+)
+
+// IgnoreFields returns an ApplyOption that removes the supplied field paths
+// from the desired state of an existing composed resource before it is
+// applied, such that their observed values are not reverted. It does not
+// affect composed resources that are being created.
+func IgnoreFields(paths ...string) resource.ApplyOption {
+	return func(_ context.Context, _, desired runtime.Object) error {
+		u, ok := desired.(interface {
+			UnstructuredContent() map[string]interface{}
+		})
+		if !ok {
+			return errors.New(errNotUnstructured)
+		}
+		for _, p := range paths {
+			if err := deleteField(u.UnstructuredContent(), p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// deleteField deletes the supplied field path from the supplied object. Paths
+// that do not exist are ignored.
+func deleteField(o map[string]interface{}, path string) error {
+	s, err := fieldpath.Parse(path)
+	if err != nil {
+		return errors.Wrapf(err, errFmtIgnoreFieldPath, path)
+	}
+
+	for _, seg := range s {
+		if seg.Type != fieldpath.SegmentField {
+			return errors.Errorf(errFmtIgnoreArray, path)
+		}
+	}
+
+	current := o
+	for i, seg := range s {
+		if i == len(s)-1 {
+			delete(current, seg.Field)
+			return nil
+		}
+		next, ok := current[seg.Field].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return nil
+}
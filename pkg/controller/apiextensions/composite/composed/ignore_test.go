@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composed
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	ucomposed "github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestIgnoreFields(t *testing.T) {
+	desired := func() *ucomposed.Unstructured {
+		return &ucomposed.Unstructured{Unstructured: unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"size":   "large",
+				"region": "us-west",
+				"tags":   []interface{}{"cool"},
+			},
+		}}}
+	}
+
+	type want struct {
+		obj map[string]interface{}
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		paths   []string
+		desired runtime.Object
+		want    want
+	}{
+		"FieldIgnored": {
+			reason:  "Ignored fields should be removed from the desired object",
+			paths:   []string{"spec.size", "spec.missing", "status.missing"},
+			desired: desired(),
+			want: want{obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"region": "us-west",
+					"tags":   []interface{}{"cool"},
+				},
+			}},
+		},
+		"ArrayElement": {
+			reason:  "Fields within arrays cannot be ignored",
+			paths:   []string{"spec.tags[0]"},
+			desired: desired(),
+			want: want{
+				obj: desired().Object,
+				err: errors.Errorf(errFmtIgnoreArray, "spec.tags[0]"),
+			},
+		},
+		"NotUnstructured": {
+			reason:  "Fields can only be ignored for unstructured resources",
+			paths:   []string{"spec.size"},
+			desired: &fake.Composed{},
+			want:    want{err: errors.New(errNotUnstructured)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := IgnoreFields(tc.paths...)(context.Background(), nil, tc.desired)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nIgnoreFields(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if u, ok := tc.desired.(*ucomposed.Unstructured); ok {
+				if diff := cmp.Diff(tc.want.obj, u.Object); diff != "" {
+					t.Errorf("\n%s\nIgnoreFields(...): -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestComposeIgnoreFields(t *testing.T) {
+	cp := &fake.Composite{ObjectMeta: metav1.ObjectMeta{Name: "composite", UID: "cool-uid"}}
+	tmpl := v1alpha1.ComposedTemplate{
+		Base:         runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Composed","spec":{"size":"large","region":"us-west"}}`)},
+		IgnoreFields: []string{"spec.size"},
+	}
+
+	// The size of the existing composed resource was tuned by hand.
+	var patched map[string]interface{}
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			u := obj.(*ucomposed.Unstructured)
+			u.Object = map[string]interface{}{
+				"apiVersion": "example.org/v1",
+				"kind":       "Composed",
+				"metadata":   map[string]interface{}{"name": "composed"},
+				"spec":       map[string]interface{}{"size": "small", "region": "us-west"},
+			}
+			return nil
+		},
+		MockPatch: func(_ context.Context, obj runtime.Object, p client.Patch, _ ...client.PatchOption) error {
+			b, err := p.Data(obj)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(b, &patched)
+		},
+	}
+
+	cd := ucomposed.New()
+	cd.SetName("composed")
+	composer := NewComposer(c, WithOverlayApplicator(NopOverlay), WithConnectionDetailFetcher(NopFetcher))
+	if _, err := composer.Compose(context.Background(), cp, cd, tmpl); err != nil {
+		t.Fatalf("Compose(...): %s", err)
+	}
+
+	want := map[string]interface{}{"region": "us-west"}
+	if diff := cmp.Diff(want, patched["spec"]); diff != "" {
+		t.Errorf("Compose(...): ignored fields should not be applied: -want spec, +got spec:\n%s", diff)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composed
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings
+const (
+	errParseNameTemplate  = "cannot parse name template"
+	errRenderNameTemplate = "cannot render name template"
+	errFmtInvalidName     = "name template produced invalid name %q: %s"
+	errFmtNotControlled   = "existing object %q is not controlled by UID %q"
+)
+
+// RenderName renders the supplied name template using the supplied composite
+// resource, which is available to the template as a map of its fields, e.g.
+// {{ .metadata.name }}. A template that references a field the composite
+// resource does not have, or that produces an invalid name, is an error.
+func RenderName(cp resource.Composite, tmpl string) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, errParseNameTemplate)
+	}
+
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cp)
+	if err != nil {
+		return "", errors.Wrap(err, errConvert)
+	}
+
+	b := &strings.Builder{}
+	if err := t.Execute(b, data); err != nil {
+		return "", errors.Wrap(err, errRenderNameTemplate)
+	}
+
+	name := b.String()
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return "", errors.Errorf(errFmtInvalidName, name, strings.Join(msgs, ", "))
+	}
+	return name, nil
+}
+
+// MustBeControlledBy requires that the current object is controlled by an
+// object with the supplied UID. Unlike resource.MustBeControllableBy it refuses
+// objects that have no controller reference, so that a composed resource named
+// by a template never takes over an existing object that happens to have the
+// name it renders.
+func MustBeControlledBy(u types.UID) resource.ApplyOption {
+	return func(_ context.Context, current, _ runtime.Object) error {
+		m := current.(metav1.Object)
+		if c := metav1.GetControllerOf(m); c == nil || c.UID != u {
+			return errors.Errorf(errFmtNotControlled, m.GetName(), u)
+		}
+		return nil
+	}
+}
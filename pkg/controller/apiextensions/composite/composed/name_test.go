@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composed
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	ucomposed "github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestRenderName(t *testing.T) {
+	cp := composite.New()
+	cp.SetName("cool")
+	cp.SetLabels(map[string]string{"env": "Production"})
+
+	type want struct {
+		name string
+		err  bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		tmpl   string
+		want   want
+	}{
+		"Rendered": {
+			reason: "A name template should be rendered using the fields of the composite resource",
+			tmpl:   "{{ .metadata.name }}-bucket",
+			want:   want{name: "cool-bucket"},
+		},
+		"ParseError": {
+			reason: "A name template that cannot be parsed should return an error",
+			tmpl:   "{{ .metadata.name",
+			want:   want{err: true},
+		},
+		"MissingField": {
+			reason: "A name template that references a missing field should return an error rather than render an empty value",
+			tmpl:   "{{ .spec.nope }}-bucket",
+			want:   want{err: true},
+		},
+		"InvalidName": {
+			reason: "A name template that renders an invalid name should return an error",
+			tmpl:   "{{ .metadata.name }}-{{ .metadata.labels.env }}",
+			want:   want{err: true},
+		},
+		"TooLong": {
+			reason: "A name template that renders a name that is too long should return an error",
+			tmpl:   "{{ .metadata.name }}-" + strings.Repeat("a", 253),
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenderName(cp, tc.tmpl)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nRenderName(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+			if diff := cmp.Diff(tc.want.name, got); diff != "" {
+				t.Errorf("\n%s\nRenderName(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestComposeNameTemplate(t *testing.T) {
+	cp := composite.New(composite.WithGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Composite"}))
+	cp.SetName("cool")
+	cp.SetUID("cool-uid")
+	nameTemplate := "{{ .metadata.name }}-bucket"
+	tmpl := v1alpha1.ComposedTemplate{
+		Base:         runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Composed"}`)},
+		NameTemplate: &nameTemplate,
+	}
+
+	// existing returns a Get function that finds an object with the rendered
+	// name and the supplied owner references.
+	existing := func(refs ...metav1.OwnerReference) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			u := obj.(*ucomposed.Unstructured)
+			u.SetName("cool-bucket")
+			u.SetOwnerReferences(refs)
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		want   error
+	}{
+		"ControlledByComposite": {
+			reason: "An existing composed resource controlled by the composite resource should be applied",
+			get:    existing(meta.AsController(meta.ReferenceTo(cp, cp.GetObjectKind().GroupVersionKind()))),
+		},
+		"NoController": {
+			reason: "An existing object with no controller should not be taken over",
+			get:    existing(),
+			want:   errors.Wrap(errors.Errorf(errFmtNotControlled, "cool-bucket", cp.GetUID()), errApply),
+		},
+		"ControlledBySomethingElse": {
+			reason: "An existing object controlled by something else should not be taken over",
+			get:    existing(meta.AsController(&corev1.ObjectReference{UID: "other-uid"})),
+			want:   errors.Wrap(errors.Errorf(errFmtNotControlled, "cool-bucket", cp.GetUID()), errApply),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &test.MockClient{MockGet: tc.get, MockPatch: test.NewMockPatchFn(nil)}
+			composer := NewComposer(c, WithOverlayApplicator(NopOverlay), WithConnectionDetailFetcher(NopFetcher))
+			_, err := composer.Compose(context.Background(), cp, ucomposed.New(), tmpl)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCompose(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
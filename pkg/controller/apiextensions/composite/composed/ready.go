@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composed
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// Error strings
+const (
+	errFmtReadinessCheckType = "unknown readiness check type %q"
+	errNoReadinessFieldPath  = "NonEmpty readiness check requires a field path"
+	errReadinessFieldPath    = "cannot read readiness check field path"
+)
+
+// IsReady returns true if the supplied composed resource passes the readiness
+// check of the supplied template. A composed resource is ready when its Ready
+// condition is True if the template has no readiness check.
+func IsReady(cd resource.Composed, t v1alpha1.ComposedTemplate) (bool, error) {
+	rc := t.ReadinessCheck
+	if rc == nil {
+		rc = &v1alpha1.ReadinessCheck{Type: v1alpha1.ReadinessCheckMatchCondition}
+	}
+
+	switch rc.Type {
+	case v1alpha1.ReadinessCheckNone:
+		return true, nil
+	case v1alpha1.ReadinessCheckMatchCondition:
+		ct := rc.ConditionType
+		if ct == "" {
+			ct = runtimev1alpha1.TypeReady
+		}
+		return resource.IsConditionTrue(cd.GetCondition(ct)), nil
+	case v1alpha1.ReadinessCheckNonEmpty:
+		if rc.FieldPath == "" {
+			return false, errors.New(errNoReadinessFieldPath)
+		}
+		c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cd)
+		if err != nil {
+			return false, errors.Wrap(err, errConvert)
+		}
+		v, err := fieldpath.Pave(c).GetValue(rc.FieldPath)
+		if fieldpath.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrap(err, errReadinessFieldPath)
+		}
+		return !isEmpty(v), nil
+	default:
+		return false, errors.Errorf(errFmtReadinessCheckType, rc.Type)
+	}
+}
+
+// isEmpty returns true if the supplied value is nil or the zero value of a
+// string, map, or slice.
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
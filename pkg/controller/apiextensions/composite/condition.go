@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// OmittedReference returns the reference stored in place of the composed
+// resource of the supplied template when its condition is not met. It refers
+// to the template's kind of resource, but not to any particular resource.
+func OmittedReference(t v1alpha1.ComposedTemplate) corev1.ObjectReference {
+	// An invalid base will be reported if the template's condition is met.
+	u := &kunstructured.Unstructured{}
+	_ = json.Unmarshal(t.Base.Raw, &u.Object)
+	return corev1.ObjectReference{APIVersion: u.GetAPIVersion(), Kind: u.GetKind()}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+const (
+	// Crossplane adds this finalizer to a composite resource only when the
+	// deletion of its composed resources is ordered. It is always the last
+	// finalizer to be processed; composed resources are not deleted until
+	// all other finalizers of their composite resource are removed, so any
+	// finalizer added by another controller can run external cleanup logic
+	// while its composed resources still exist. Composite resources without
+	// this finalizer have their composed resources garbage collected once
+	// they are gone, and thus after all of their finalizers are removed.
+	finalizer = "finalizer.apiextensions.crossplane.io"
+
+	// AnnotationKeyDeletionOrder orders the deletion of the resources
+	// composed by a composite resource. It may be set on the base of a
+	// composed template. When a composite resource is deleted its composed
+	// resources with the lowest deletion order are deleted first, and
+	// resources with a higher deletion order are not deleted until they are
+	// gone. Composed resources without this annotation have a deletion order
+	// of zero.
+	AnnotationKeyDeletionOrder = "crossplane.io/deletion-order"
+)
+
+// Error strings.
+const (
+	errGetComposed    = "cannot get composed resource"
+	errDeleteComposed = "cannot delete composed resource"
+)
+
+// finalizers that are processed by the Kubernetes garbage collector. We don't
+// wait for these, because the garbage collector waits for composed resources.
+var gcFinalizers = map[string]bool{
+	metav1.FinalizerDeleteDependents: true,
+	metav1.FinalizerOrphanDependents: true,
+}
+
+// OtherFinalizers returns the finalizers of the supplied composite resource that
+// must be removed before its composed resources are deleted, in the order they
+// appear. Our own finalizer and those processed by the Kubernetes garbage
+// collector are omitted.
+func OtherFinalizers(cr metav1.Object) []string {
+	var other []string
+	for _, f := range cr.GetFinalizers() {
+		if f == finalizer || gcFinalizers[f] {
+			continue
+		}
+		other = append(other, f)
+	}
+	return other
+}
+
+// A ComposedDeleter deletes the resources composed by a composite resource.
+type ComposedDeleter interface {
+	// DeleteComposed resources of the supplied composite resource. Returns
+	// true once all of its composed resources are gone.
+	DeleteComposed(ctx context.Context, cr resource.Composite) (bool, error)
+}
+
+// A ComposedDeleterFn is a function that satisfies the ComposedDeleter
+// interface.
+type ComposedDeleterFn func(ctx context.Context, cr resource.Composite) (bool, error)
+
+// DeleteComposed calls ComposedDeleterFn.
+func (fn ComposedDeleterFn) DeleteComposed(ctx context.Context, cr resource.Composite) (bool, error) {
+	return fn(ctx, cr)
+}
+
+// NewAPIOrderedDeleter returns a ComposedDeleter that deletes composed
+// resources in order of their deletion order annotation.
+func NewAPIOrderedDeleter(c client.Client) *APIOrderedDeleter {
+	return &APIOrderedDeleter{client: c}
+}
+
+// An APIOrderedDeleter deletes composed resources in order of their deletion
+// order annotation.
+type APIOrderedDeleter struct {
+	client client.Client
+}
+
+// DeleteComposed deletes the composed resources of the supplied composite
+// resource that have the lowest deletion order of those that still exist.
+// Composed resources that are not controlled by the composite resource are
+// ignored.
+func (d *APIOrderedDeleter) DeleteComposed(ctx context.Context, cr resource.Composite) (bool, error) {
+	existing := map[int][]resource.Composed{}
+	next, found := 0, false
+
+	refs := cr.GetResourceReferences()
+	for i := range refs {
+		// The templates of omitted composed resources have empty
+		// references.
+		if refs[i].Name == "" {
+			continue
+		}
+		cd := composed.New(composed.FromReference(refs[i]))
+		err := d.client.Get(ctx, meta.NamespacedNameOf(&refs[i]), cd)
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrap(err, errGetComposed)
+		}
+		if !metav1.IsControlledBy(cd, cr) {
+			continue
+		}
+
+		o := DeletionOrder(cd)
+		if !found || o < next {
+			next, found = o, true
+		}
+		existing[o] = append(existing[o], cd)
+	}
+
+	if !found {
+		return true, nil
+	}
+
+	for _, cd := range existing[next] {
+		if meta.WasDeleted(cd) {
+			continue
+		}
+		if err := d.client.Delete(ctx, cd); resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrap(err, errDeleteComposed)
+		}
+	}
+	return false, nil
+}
+
+// DeleteControlled deletes the referenced composed resource, if it exists and
+// is controlled by the supplied composite resource.
+func DeleteControlled(ctx context.Context, c client.Client, cr resource.Composite, ref corev1.ObjectReference) error {
+	cd := composed.New(composed.FromReference(ref))
+	err := c.Get(ctx, meta.NamespacedNameOf(&ref), cd)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetComposed)
+	}
+	if !metav1.IsControlledBy(cd, cr) {
+		return nil
+	}
+	return errors.Wrap(resource.IgnoreNotFound(c.Delete(ctx, cd)), errDeleteComposed)
+}
+
+// DeletionOrder returns the deletion order of the supplied composed resource.
+// Composed resources without a valid deletion order annotation have a deletion
+// order of zero.
+func DeletionOrder(o metav1.Object) int {
+	i, err := strconv.Atoi(o.GetAnnotations()[AnnotationKeyDeletionOrder])
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// OrderedDeletion returns true if any of the templates of the supplied
+// composition specify a deletion order.
+func OrderedDeletion(comp *v1alpha1.Composition) bool {
+	for _, t := range comp.Spec.To {
+		cd := composed.New()
+		if err := json.Unmarshal(t.Base.Raw, cd); err != nil {
+			continue
+		}
+		if _, ok := cd.GetAnnotations()[AnnotationKeyDeletionOrder]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestDeleteComposed(t *testing.T) {
+	cr := composite.New()
+	cr.SetName("cool")
+	cr.SetUID("cool-uid")
+	cr.SetResourceReferences([]corev1.ObjectReference{
+		{APIVersion: "example.org/v1", Kind: "Subnet", Name: "cool-subnet"},
+		{APIVersion: "example.org/v1", Kind: "Instance", Name: "cool-instance"},
+	})
+	controller := meta.AsController(meta.ReferenceTo(cr, schema.GroupVersionKind{}))
+
+	// The instance must be deleted before the subnet it runs in.
+	order := map[string]string{"cool-subnet": "1"}
+
+	// teardown returns a client whose composed resources are those in the
+	// supplied set, and that records which of them were deleted.
+	teardown := func(exists map[string]bool, deleted *[]string) client.Client {
+		return &test.MockClient{
+			MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+				if !exists[key.Name] {
+					return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}
+				u := obj.(metav1.Object)
+				u.SetName(key.Name)
+				u.SetOwnerReferences(append(u.GetOwnerReferences(), controller))
+				if o, ok := order[key.Name]; ok {
+					u.SetAnnotations(map[string]string{AnnotationKeyDeletionOrder: o})
+				}
+				return nil
+			},
+			MockDelete: func(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+				*deleted = append(*deleted, obj.(metav1.Object).GetName())
+				return nil
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		exists  map[string]bool
+		gone    bool
+		deleted []string
+	}{
+		"DeleteLowestOrderFirst": {
+			reason:  "Only the composed resource with the lowest deletion order should be deleted while both exist",
+			exists:  map[string]bool{"cool-subnet": true, "cool-instance": true},
+			deleted: []string{"cool-instance"},
+		},
+		"DeleteNextOrderOnceGone": {
+			reason:  "The composed resource with the next deletion order should be deleted once those before it are gone",
+			exists:  map[string]bool{"cool-subnet": true},
+			deleted: []string{"cool-subnet"},
+		},
+		"AllGone": {
+			reason: "Deletion should be complete once all composed resources are gone",
+			exists: map[string]bool{},
+			gone:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			d := NewAPIOrderedDeleter(teardown(tc.exists, &deleted))
+			gone, err := d.DeleteComposed(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\nDeleteComposed(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.gone, gone); diff != "" {
+				t.Errorf("\n%s\nDeleteComposed(...): -want gone, +got gone:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nDeleteComposed(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("GetError", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		d := NewAPIOrderedDeleter(&test.MockClient{MockGet: test.NewMockGetFn(errBoom)})
+		_, err := d.DeleteComposed(context.Background(), cr)
+		if diff := cmp.Diff(errors.Wrap(errBoom, errGetComposed), err, test.EquateErrors()); diff != "" {
+			t.Errorf("DeleteComposed(...): -want error, +got error:\n%s", diff)
+		}
+	})
+}
+
+func TestOrderedDeletion(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		base   string
+		want   bool
+	}{
+		"Ordered": {
+			reason: "A composition with a template that specifies a deletion order should be ordered",
+			base:   `{"apiVersion":"example.org/v1","kind":"Subnet","metadata":{"annotations":{"crossplane.io/deletion-order":"1"}}}`,
+			want:   true,
+		},
+		"Unordered": {
+			reason: "A composition with no templates that specify a deletion order should not be ordered",
+			base:   `{"apiVersion":"example.org/v1","kind":"Subnet"}`,
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			comp := &v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{To: []v1alpha1.ComposedTemplate{
+				{Base: runtime.RawExtension{Raw: []byte(tc.base)}},
+			}}}
+			if diff := cmp.Diff(tc.want, OrderedDeletion(comp)); diff != "" {
+				t.Errorf("\n%s\nOrderedDeletion(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOtherFinalizers(t *testing.T) {
+	cr := composite.New()
+	cr.SetFinalizers([]string{"example.org/b", finalizer, metav1.FinalizerDeleteDependents, "example.org/a"})
+
+	want := []string{"example.org/b", "example.org/a"}
+	if diff := cmp.Diff(want, OtherFinalizers(cr)); diff != "" {
+		t.Errorf("OtherFinalizers(...): -want, +got:\n%s", diff)
+	}
+}
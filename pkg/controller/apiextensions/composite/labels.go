@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// WithPropagatedLabels returns the supplied template with patches that
+// propagate the supplied label keys from the composite resource to the composed
+// resource. The patches are applied before those of the template, so that the
+// template's patches take precedence. Labels that are set by the base of the
+// template are not propagated.
+func WithPropagatedLabels(t v1alpha1.ComposedTemplate, keys []string) v1alpha1.ComposedTemplate {
+	if len(keys) == 0 {
+		return t
+	}
+
+	// An invalid base will be reported when the template is rendered.
+	base := &kunstructured.Unstructured{}
+	_ = json.Unmarshal(t.Base.Raw, &base.Object)
+	set := base.GetLabels()
+
+	patches := make([]v1alpha1.Patch, 0, len(keys)+len(t.Patches))
+	for _, k := range keys {
+		if _, ok := set[k]; ok {
+			continue
+		}
+		path := fmt.Sprintf("metadata.labels[%s]", k)
+		patches = append(patches, v1alpha1.Patch{FromFieldPath: path, ToFieldPath: path})
+	}
+	t.Patches = append(patches, t.Patches...)
+	return t
+}
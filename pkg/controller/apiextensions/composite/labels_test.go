@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	composedctrl "github.com/crossplane/crossplane/pkg/controller/apiextensions/composite/composed"
+)
+
+func TestWithPropagatedLabels(t *testing.T) {
+	cp := composite.New()
+	cp.SetName("cool")
+	cp.SetLabels(map[string]string{
+		"example.org/cost-center": "42",
+		"team":                    "platform",
+		"env":                     "production",
+		"unpropagated":            "true",
+	})
+
+	cases := map[string]struct {
+		reason string
+		keys   []string
+		tmpl   v1alpha1.ComposedTemplate
+		want   map[string]string
+	}{
+		"Propagated": {
+			reason: "Labels of the composite resource with the supplied keys should be propagated",
+			keys:   []string{"example.org/cost-center", "team", "missing"},
+			tmpl: v1alpha1.ComposedTemplate{
+				Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Bucket"}`)},
+			},
+			want: map[string]string{"example.org/cost-center": "42", "team": "platform"},
+		},
+		"BaseTakesPrecedence": {
+			reason: "Labels set by the base of a template should take precedence over propagated labels",
+			keys:   []string{"team", "env"},
+			tmpl: v1alpha1.ComposedTemplate{
+				Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"labels":{"team":"storage"}}}`)},
+			},
+			want: map[string]string{"team": "storage", "env": "production"},
+		},
+		"PatchTakesPrecedence": {
+			reason: "Labels set by the patches of a template should take precedence over propagated labels",
+			keys:   []string{"team", "env"},
+			tmpl: v1alpha1.ComposedTemplate{
+				Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Bucket"}`)},
+				Patches: []v1alpha1.Patch{
+					{FromFieldPath: "metadata.name", ToFieldPath: "metadata.labels[team]"},
+				},
+			},
+			want: map[string]string{"team": "cool", "env": "production"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cd := composed.New()
+			c := composedctrl.NewComposer(nil)
+			if err := c.Render(cp, cd, WithPropagatedLabels(tc.tmpl, tc.keys)); err != nil {
+				t.Fatalf("\n%s\nRender(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, cd.GetLabels()); diff != "" {
+				t.Errorf("\n%s\nWithPropagatedLabels(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
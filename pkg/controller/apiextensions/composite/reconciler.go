@@ -23,6 +23,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -45,6 +46,7 @@ import (
 const (
 	shortWait = 30 * time.Second
 	longWait  = 1 * time.Minute
+	quotaWait = 5 * time.Minute
 	timeout   = 2 * time.Minute
 )
 
@@ -58,6 +60,16 @@ const (
 	errConfigure    = "cannot configure composite infrastructure resource"
 	errReconcile    = "cannot reconcile composed infrastructure resource"
 	errPublish      = "cannot publish connection details"
+	errFmtAggregate = "cannot apply status aggregation %d"
+	errFmtCondition = "cannot evaluate condition of composed resource at index %d"
+	errOmit         = "cannot delete omitted composed infrastructure resource"
+	errEmpty        = "Composition has no target resources and does not allow empty"
+
+	errFmtTooManyComposed = "Composition has %d target resources, more than the maximum of %d"
+
+	errAddFinalizer    = "cannot add composite infrastructure resource finalizer"
+	errRemoveFinalizer = "cannot remove composite infrastructure resource finalizer"
+	errDelete          = "cannot delete composed infrastructure resources"
 )
 
 // Event reasons.
@@ -65,8 +77,20 @@ const (
 	reasonResolve event.Reason = "SelectComposition"
 	reasonCompose event.Reason = "ComposeResources"
 	reasonPublish event.Reason = "PublishConnectionSecret"
+	reasonDelete  event.Reason = "DeleteResources"
 )
 
+// AnnotationKeyPaused may be set to "true" on a composite resource to pause
+// its reconciliation. Its composed resources are left as they are until the
+// annotation is removed.
+const AnnotationKeyPaused = "crossplane.io/paused"
+
+// IsPaused returns true if reconciliation of the supplied composite resource
+// is paused.
+func IsPaused(cr resource.Composite) bool {
+	return cr.GetAnnotations()[AnnotationKeyPaused] == "true"
+}
+
 // ControllerName returns the recommended name for controllers that use this
 // package to reconcile a particular kind of composite infrastructure resource.
 func ControllerName(name string) string {
@@ -99,18 +123,43 @@ type Composer interface {
 	Compose(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (composedctrl.Observation, error)
 }
 
+// A ComposerFn is a function that satisfies the Composer interface.
+type ComposerFn func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (composedctrl.Observation, error)
+
+// Compose calls ComposerFn.
+func (fn ComposerFn) Compose(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+	return fn(ctx, cp, cd, t)
+}
+
 // SelectorResolver selects the composition reference with the information given
 // as selector.
 type SelectorResolver interface {
 	ResolveSelector(ctx context.Context, cr resource.Composite) error
 }
 
+// A SelectorResolverFn is a function that satisfies the SelectorResolver
+// interface.
+type SelectorResolverFn func(ctx context.Context, cr resource.Composite) error
+
+// ResolveSelector calls SelectorResolverFn.
+func (fn SelectorResolverFn) ResolveSelector(ctx context.Context, cr resource.Composite) error {
+	return fn(ctx, cr)
+}
+
 // A Configurator configures a composite resource using its
 // composition.
 type Configurator interface {
 	Configure(ctx context.Context, cr resource.Composite, cp *v1alpha1.Composition) error
 }
 
+// A ConfiguratorFn is a function that satisfies the Configurator interface.
+type ConfiguratorFn func(ctx context.Context, cr resource.Composite, cp *v1alpha1.Composition) error
+
+// Configure calls ConfiguratorFn.
+func (fn ConfiguratorFn) Configure(ctx context.Context, cr resource.Composite, cp *v1alpha1.Composition) error {
+	return fn(ctx, cr, cp)
+}
+
 // ReconcilerOption is used to configure the Reconciler.
 type ReconcilerOption func(*Reconciler)
 
@@ -152,6 +201,46 @@ func WithConnectionPublisher(p ConnectionPublisher) ReconcilerOption {
 	}
 }
 
+// WithFinalizer specifies how the Reconciler should add and remove finalizers
+// to and from composite resources.
+func WithFinalizer(f resource.Finalizer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composite.Finalizer = f
+	}
+}
+
+// WithComposedDeleter specifies how the Reconciler should delete the composed
+// resources of composite resources that are being deleted.
+func WithComposedDeleter(d ComposedDeleter) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composite.ComposedDeleter = d
+	}
+}
+
+// WithRequeueJitter specifies that the Reconciler should add a random jitter
+// of up to the supplied maximum to the interval after which it requeues a
+// composite resource. This spreads out reconciles of composite resources that
+// would otherwise be requeued at the same time. The supplied function must
+// return a non-negative pseudo-random number less than n, like rand.Int63n.
+func WithRequeueJitter(max time.Duration, rnd func(n int64) int64) ReconcilerOption {
+	return func(r *Reconciler) {
+		if max <= 0 {
+			return
+		}
+		r.jitter = func() time.Duration { return time.Duration(rnd(int64(max) + 1)) }
+	}
+}
+
+// WithMaxComposedResources specifies the maximum number of resources that may
+// be composed by a single composite resource. A composite resource whose
+// Composition has more target resources will not compose any of them. Zero
+// means there is no maximum.
+func WithMaxComposedResources(max int64) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.maxComposed = max
+	}
+}
+
 // WithComposer specifies how the Reconciler should compose resources.
 func WithComposer(rc Composer) ReconcilerOption {
 	return func(r *Reconciler) {
@@ -163,6 +252,8 @@ type compositeResource struct {
 	SelectorResolver
 	Configurator
 	ConnectionPublisher
+	resource.Finalizer
+	ComposedDeleter
 }
 
 // NewReconciler returns a new Reconciler of composite infrastructure resources.
@@ -180,12 +271,16 @@ func NewReconciler(mgr manager.Manager, of resource.CompositeKind, opts ...Recon
 			SelectorResolver:    NewAPISelectorResolver(kube),
 			Configurator:        NewAPIConfigurator(kube),
 			ConnectionPublisher: NewAPIFilteredSecretPublisher(kube, []string{}),
+			Finalizer:           resource.NewAPIFinalizer(kube, finalizer),
+			ComposedDeleter:     NewAPIOrderedDeleter(kube),
 		},
 
 		resource: composedctrl.NewComposer(kube),
 
 		log:    logging.NewNopLogger(),
 		record: event.NewNopRecorder(),
+
+		jitter: func() time.Duration { return 0 },
 	}
 
 	for _, f := range opts {
@@ -204,10 +299,21 @@ type Reconciler struct {
 
 	log    logging.Logger
 	record event.Recorder
+
+	jitter      func() time.Duration
+	maxComposed int64
 }
 
 // Reconcile a composite infrastructure resource.
-func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) { // nolint:gocyclo
+func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.reconcile(req)
+	if result.RequeueAfter > 0 {
+		result.RequeueAfter += r.jitter()
+	}
+	return result, err
+}
+
+func (r *Reconciler) reconcile(req reconcile.Request) (reconcile.Result, error) { // nolint:gocyclo
 	// NOTE(negz): Like most Reconcile methods, this one is over our cyclomatic
 	// complexity goal. Be wary when adding branches, and look for functionality
 	// that could be reasonably moved into an injected dependency.
@@ -230,13 +336,73 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		"name", cr.GetName(),
 	)
 
+	// A paused composite resource is neither composed nor deleted. We'll be
+	// queued again when the annotation is removed.
+	if IsPaused(cr) {
+		log.Debug("Reconciliation is paused")
+		if cr.GetCondition(runtimev1alpha1.TypeSynced).Equal(v1alpha1.ReconcilePaused()) {
+			// We've already reported that we're paused.
+			return reconcile.Result{Requeue: false}, nil
+		}
+		cr.SetConditions(v1alpha1.ReconcilePaused())
+		return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	if meta.WasDeleted(cr) {
+		log = log.WithValues("deletion-timestamp", cr.GetDeletionTimestamp())
+
+		// Composed resources are garbage collected once their composite
+		// resource is gone, unless we're ordering their deletion.
+		if !meta.FinalizerExists(cr, finalizer) {
+			return reconcile.Result{Requeue: false}, nil
+		}
+
+		// Other finalizers may need our composed resources to exist in order
+		// to clean up, so we don't delete them until those finalizers are
+		// removed. We'll be queued when the finalizers change.
+		if f := OtherFinalizers(cr); len(f) > 0 {
+			log.Debug("Waiting for other finalizers to be removed", "finalizers", f)
+			cr.SetConditions(runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileSuccess())
+			return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
+		gone, err := r.composite.DeleteComposed(ctx, cr)
+		if err != nil {
+			log.Debug(errDelete, "error", err)
+			r.record.Event(cr, event.Warning(reasonDelete, err))
+			cr.SetConditions(runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileError(errors.Wrap(err, errDelete)))
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
+		if !gone {
+			// We may not be watching all kinds of composed resource, so we
+			// poll until the ones we deleted are gone.
+			log.Debug("Waiting for composed resources to be deleted", "requeue-after", time.Now().Add(shortWait))
+			cr.SetConditions(runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileSuccess())
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
+		if err := r.composite.RemoveFinalizer(ctx, cr); err != nil {
+			log.Debug(errRemoveFinalizer, "error", err)
+			r.record.Event(cr, event.Warning(reasonDelete, err))
+			cr.SetConditions(runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileError(errors.Wrap(err, errRemoveFinalizer)))
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
+		log.Debug("Successfully deleted composed resources")
+		r.record.Event(cr, event.Normal(reasonDelete, "Successfully deleted composed resources"))
+		return reconcile.Result{Requeue: false}, nil
+	}
+
 	if err := r.composite.ResolveSelector(ctx, cr); err != nil {
 		log.Debug(errSelectComp, "error", err)
 		r.record.Event(cr, event.Warning(reasonResolve, err))
 		cr.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errSelectComp)))
 		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 	}
-	r.record.Event(cr, event.Normal(reasonResolve, "Successfully selected composition"))
+	if ref := cr.GetCompositionReference(); ref != nil {
+		r.record.Event(cr, event.Normal(reasonResolve, "Successfully selected composition "+ref.Name))
+	}
 
 	// TODO(muvaf): We should lock the deletion of Composition via finalizer
 	// because its deletion will break the field propagation.
@@ -261,6 +427,37 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		"composition-name", comp.GetName(),
 	)
 
+	// We only need to finalize composite resources whose composed resources
+	// must be deleted in order. Others are garbage collected.
+	if OrderedDeletion(comp) {
+		if err := r.composite.AddFinalizer(ctx, cr); err != nil {
+			log.Debug(errAddFinalizer, "error", err)
+			r.record.Event(cr, event.Warning(reasonCompose, err))
+			cr.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errAddFinalizer)))
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+	}
+
+	// A Composition with no target resources is most likely a mistake, unless
+	// it explicitly says otherwise.
+	if len(comp.Spec.To) == 0 && !comp.Spec.AllowEmpty {
+		log.Debug(errEmpty)
+		r.record.Event(cr, event.Warning(reasonCompose, errors.New(errEmpty)))
+		cr.SetConditions(runtimev1alpha1.ReconcileError(errors.New(errEmpty)))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	// Composing a very large number of resources could overwhelm the API
+	// server, so we refuse to compose any of them. Retrying quickly won't help
+	// until the Composition or the maximum changes.
+	if n := int64(len(comp.Spec.To)); r.maxComposed > 0 && n > r.maxComposed {
+		err := errors.Errorf(errFmtTooManyComposed, n, r.maxComposed)
+		log.Debug("Too many composed resources", "error", err)
+		r.record.Event(cr, event.Warning(reasonCompose, err))
+		cr.SetConditions(runtimev1alpha1.ReconcileError(err))
+		return reconcile.Result{RequeueAfter: longWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
 	// TODO(muvaf): Since the composed reconciler returns only reference, it can
 	// be parallelized via go routines.
 
@@ -271,11 +468,62 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 	refs := make([]corev1.ObjectReference, len(comp.Spec.To))
 	copy(refs, cr.GetResourceReferences())
 	conn := managed.ConnectionDetails{}
-	ready := 0
+	ready, omitted := 0, 0
+	var quota *runtimev1alpha1.Condition
+	cds := make([]runtime.Object, len(refs))
 	for i, ref := range refs {
-		tmpl := comp.Spec.To[i]
+		tmpl := WithPropagatedLabels(comp.Spec.To[i], comp.Spec.PropagateLabels)
+
+		cd := composed.New(composed.FromReference(ref))
+		cds[i] = cd
+
+		include := true
+		if c := tmpl.Condition; c != nil {
+			met, err := c.Met(cr)
+			if err != nil {
+				err = errors.Wrapf(err, errFmtCondition, i)
+				log.Debug("Cannot evaluate composed resource condition", "error", err)
+				r.record.Event(cr, event.Warning(reasonCompose, err))
+				cr.SetConditions(runtimev1alpha1.ReconcileError(err))
+				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+			}
+			include = met
+		}
 
-		obs, err := r.resource.Compose(ctx, cr, composed.New(composed.FromReference(ref)), tmpl)
+		if !include {
+			omitted++
+
+			// This composed resource's condition is no longer met, so we
+			// delete it and forget our reference to it. It will be composed
+			// again with a new name if its condition is met again.
+			if ref.Name != "" {
+				if err := DeleteControlled(ctx, r.client, cr, ref); err != nil {
+					log.Debug(errOmit, "error", err)
+					r.record.Event(cr, event.Warning(reasonCompose, err))
+					cr.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errOmit)))
+					return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+				}
+			}
+
+			// Empty references are dropped when they're stored, so we keep an
+			// unnamed reference in place of an omitted composed resource to
+			// ensure the references of the templates after it don't move.
+			omittedRef := OmittedReference(tmpl)
+			if cmp.Equal(ref, omittedRef) {
+				continue
+			}
+			refs[i] = omittedRef
+			cr.SetResourceReferences(refs)
+			if err := r.client.Update(ctx, cr); err != nil {
+				log.Debug(errUpdate, "error", err)
+				r.record.Event(cr, event.Warning(reasonCompose, err))
+				cr.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errUpdate)))
+				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+			}
+			continue
+		}
+
+		obs, err := r.resource.Compose(ctx, cr, cd, tmpl)
 		if err != nil {
 			log.Debug(errReconcile, "error", err)
 			r.record.Event(cr, event.Warning(reasonCompose, err))
@@ -291,6 +539,10 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 			ready++
 		}
 
+		if composedctrl.IsQuotaExceeded(obs.Synced) {
+			quota = &obs.Synced
+		}
+
 		// We need to update our composite resource with any new or updated
 		// references to the resources it composes. We do this immediately after
 		// each composed resource has been reconciled to ensure that we don't
@@ -310,6 +562,20 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		}
 	}
 
+	for i, a := range comp.Spec.StatusAggregations {
+		if err := a.Aggregate(cds, cr); err != nil {
+			err = errors.Wrapf(err, errFmtAggregate, i)
+			log.Debug("Cannot aggregate composed resource status", "error", err)
+			r.record.Event(cr, event.Warning(reasonCompose, err))
+			cr.SetConditions(runtimev1alpha1.ReconcileError(err))
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
+		}
+	}
+
+	if cd := comp.Spec.ConnectionDetails; cd != nil {
+		conn = FilterConnectionDetails(conn, cd.Filter)
+	}
+
 	if err := r.composite.PublishConnection(ctx, cr, conn); err != nil {
 		log.Debug(errPublish, "error", err)
 		r.record.Event(cr, event.Warning(reasonPublish, err))
@@ -323,11 +589,22 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 	// TODO(negz): Add a bespoke 'partial' TypeReady condition?
 	wait := longWait
 	switch {
+	case quota != nil:
+		// Retrying quickly won't help until quota is freed or raised, so we
+		// back off for longer than usual.
+		log.Debug("Composed resource provider quota exceeded", "message", quota.Message)
+		cr.SetConditions(v1alpha1.QuotaExceeded(quota.Message))
+		wait = quotaWait
+	case ready == len(refs)-omitted:
+		// Note that this includes a Composition that intentionally composes
+		// no resources, or whose conditions omit all of its resources.
+		cr.SetConditions(runtimev1alpha1.Available())
 	case ready == 0:
 		cr.SetConditions(runtimev1alpha1.Creating())
 		wait = shortWait
-	case ready == len(refs):
-		cr.SetConditions(runtimev1alpha1.Available())
+	}
+	if i := comp.Spec.ReadinessPollInterval; i != nil && quota == nil && ready < len(refs)-omitted {
+		wait = i.Duration
 	}
 
 	r.record.Event(cr, event.Normal(reasonPublish, "Successfully published connection details"))
@@ -0,0 +1,454 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	composedctrl "github.com/crossplane/crossplane/pkg/controller/apiextensions/composite/composed"
+)
+
+func TestReconcile(t *testing.T) {
+	kind := resource.CompositeKind(schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XExample"})
+
+	// withComposition returns a MockGet that returns a composite resource
+	// that references a Composition, and the supplied Composition.
+	withComposition := func(comp v1alpha1.Composition) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *kunstructured.Unstructured:
+				cr := composite.New()
+				cr.SetCompositionReference(&corev1.ObjectReference{Name: "cool-composition"})
+				o.Object = cr.Object
+			case *v1alpha1.Composition:
+				*o = comp
+			}
+			return nil
+		}
+	}
+
+	// withConditions returns a MockStatusUpdate that asserts the composite
+	// resource has the supplied conditions.
+	withConditions := func(t *testing.T, c ...runtimev1alpha1.Condition) test.MockStatusUpdateFn {
+		return func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			cr := &composite.Unstructured{Unstructured: *obj.(*kunstructured.Unstructured)}
+			for _, want := range c {
+				if diff := cmp.Diff(want, cr.GetCondition(want.Type), test.EquateConditions()); diff != "" {
+					t.Errorf("Status().Update(): -want, +got:\n%s", diff)
+				}
+			}
+			return nil
+		}
+	}
+
+	// beingDeleted returns a MockGet that returns a composite resource that is
+	// being deleted and that has our finalizer.
+	beingDeleted := func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+		cr := composite.New()
+		now := metav1.Now()
+		cr.SetDeletionTimestamp(&now)
+		cr.SetFinalizers([]string{finalizer})
+		obj.(*kunstructured.Unstructured).Object = cr.Object
+		return nil
+	}
+
+	quotaErr := errors.New("googleapi: Error 403: Quota 'CPUS' exceeded. Limit: 24.0 in region us-central1., quotaExceeded")
+
+	controller := true
+
+	noop := func(_ context.Context, _ resource.Composite) error { return nil }
+	noopConfigure := func(_ context.Context, _ resource.Composite, _ *v1alpha1.Composition) error { return nil }
+
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		client   func(t *testing.T) client.Client
+		composer Composer
+		deleter  ComposedDeleter
+		opts     []ReconcilerOption
+		want     want
+	}{
+		"IntentionallyEmptyComposition": {
+			reason: "A composite resource whose Composition allows empty should be available without composing any resources",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet:          withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{AllowEmpty: true}}),
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
+				}
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: longWait},
+			},
+		},
+		"UnexpectedlyEmptyComposition": {
+			reason: "A composite resource whose Composition has no target resources and does not allow empty should not become available",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: withComposition(v1alpha1.Composition{}),
+					MockStatusUpdate: func(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+						cr := &composite.Unstructured{Unstructured: *obj.(*kunstructured.Unstructured)}
+						if cr.GetCondition(runtimev1alpha1.TypeReady).Status == corev1.ConditionTrue {
+							t.Errorf("Status().Update(): composite resource with unexpectedly empty Composition should not be ready")
+						}
+						return withConditions(t, runtimev1alpha1.ReconcileError(errors.New(errEmpty)))(ctx, obj, opts...)
+					},
+				}
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: shortWait},
+			},
+		},
+		"TooManyComposedResources": {
+			reason: "A composite resource whose Composition has more target resources than the maximum should not compose any of them",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet:          withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{To: []v1alpha1.ComposedTemplate{{}, {}}}}),
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.ReconcileError(errors.Errorf(errFmtTooManyComposed, 2, 1))),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				t.Errorf("Compose(...): a composite resource with too many composed resources should not compose resources")
+				return composedctrl.Observation{}, nil
+			}),
+			opts: []ReconcilerOption{WithMaxComposedResources(1)},
+			want: want{
+				r: reconcile.Result{RequeueAfter: longWait},
+			},
+		},
+		"ComposedResourceQuotaExceeded": {
+			reason: "A composite resource should back off and report when the provider of a composed resource exceeds its quota",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet:          withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{To: []v1alpha1.ComposedTemplate{{}}}}),
+					MockStatusUpdate: withConditions(t, v1alpha1.QuotaExceeded(quotaErr.Error())),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				synced := runtimev1alpha1.ReconcileError(quotaErr)
+				synced.Reason = v1alpha1.ReasonComposedQuotaExceeded
+				return composedctrl.Observation{Synced: synced}, nil
+			}),
+			want: want{
+				r: reconcile.Result{RequeueAfter: quotaWait},
+			},
+		},
+		"ReadinessPollInterval": {
+			reason: "A composite resource whose composed resources are not ready should be polled at the interval its Composition specifies",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{
+						To:                    []v1alpha1.ComposedTemplate{{}},
+						ReadinessPollInterval: &metav1.Duration{Duration: 5 * time.Second},
+					}}),
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				return composedctrl.Observation{Ready: false}, nil
+			}),
+			want: want{
+				r: reconcile.Result{RequeueAfter: 5 * time.Second},
+			},
+		},
+		"OmittedComposedResourceDeleted": {
+			reason: "A composed resource whose condition is no longer met should be deleted and forgotten",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						switch o := obj.(type) {
+						case *kunstructured.Unstructured:
+							cr := composite.New()
+							cr.SetUID("cool-uid")
+							cr.SetCompositionReference(&corev1.ObjectReference{Name: "cool-composition"})
+							cr.SetResourceReferences([]corev1.ObjectReference{{APIVersion: "example.org/v1", Kind: "Replica", Name: "cool-replica"}})
+							o.Object = cr.Object
+						case *composed.Unstructured:
+							o.SetName("cool-replica")
+							o.SetOwnerReferences([]metav1.OwnerReference{{UID: "cool-uid", Controller: &controller}})
+						case *v1alpha1.Composition:
+							*o = v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{To: []v1alpha1.ComposedTemplate{{
+								Base:      runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Replica"}`)},
+								Condition: &v1alpha1.TemplateCondition{FieldPath: "spec.highAvailability", Equals: "true"},
+							}}}}
+						}
+						return nil
+					},
+					MockDelete: func(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+						if n := obj.(metav1.Object).GetName(); n != "cool-replica" {
+							t.Errorf("Delete(): want cool-replica, got %s", n)
+						}
+						return nil
+					},
+					MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						cr := &composite.Unstructured{Unstructured: *obj.(*kunstructured.Unstructured)}
+						// The omitted resource's reference is kept, without a name.
+						want := []corev1.ObjectReference{{APIVersion: "example.org/v1", Kind: "Replica"}}
+						if diff := cmp.Diff(want, cr.GetResourceReferences()); diff != "" {
+							t.Errorf("Update(): -want refs, +got refs:\n%s", diff)
+						}
+						return nil
+					},
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				t.Errorf("Compose(...): a composed resource whose condition is not met should not be composed")
+				return composedctrl.Observation{}, nil
+			}),
+			want: want{
+				r: reconcile.Result{RequeueAfter: longWait},
+			},
+		},
+		"StatusAggregated": {
+			reason: "Fields of the composed resources should be aggregated into the status of the composite resource",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{
+						To: []v1alpha1.ComposedTemplate{{}, {}},
+						StatusAggregations: []v1alpha1.StatusAggregation{
+							{FromFieldPath: "status.atProvider.endpoint", ToFieldPath: "status.endpoints"},
+						},
+					}}),
+					MockUpdate: test.NewMockUpdateFn(nil),
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						got, _, _ := kunstructured.NestedSlice(obj.(*kunstructured.Unstructured).Object, "status", "endpoints")
+						want := []interface{}{"cool.example.org", "cool.example.org"}
+						if diff := cmp.Diff(want, got); diff != "" {
+							t.Errorf("Status().Update(): -want endpoints, +got endpoints:\n%s", diff)
+						}
+						return nil
+					},
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, cd resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				cd.(*composed.Unstructured).Object["status"] = map[string]interface{}{
+					"atProvider": map[string]interface{}{"endpoint": "cool.example.org"},
+				}
+				return composedctrl.Observation{Ready: true}, nil
+			}),
+			want: want{
+				r: reconcile.Result{RequeueAfter: longWait},
+			},
+		},
+		"StatusAggregationError": {
+			reason: "A composite resource should report an error when a status aggregation cannot be applied",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: withComposition(v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{
+						To: []v1alpha1.ComposedTemplate{{}},
+						StatusAggregations: []v1alpha1.StatusAggregation{
+							{FromFieldPath: "status.atProvider.endpoint", ToFieldPath: "spec.endpoints"},
+						},
+					}}),
+					MockUpdate:       test.NewMockUpdateFn(nil),
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.ReconcileError(errors.Wrapf(errors.New("status aggregations must write to a status field"), errFmtAggregate, 0))),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				return composedctrl.Observation{Ready: true}, nil
+			}),
+			want: want{
+				r: reconcile.Result{RequeueAfter: shortWait},
+			},
+		},
+		"Paused": {
+			reason: "A paused composite resource should report that it is paused without composing any resources",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						cr := composite.New()
+						cr.SetAnnotations(map[string]string{AnnotationKeyPaused: "true"})
+						obj.(*kunstructured.Unstructured).Object = cr.Object
+						return nil
+					},
+					MockStatusUpdate: withConditions(t, v1alpha1.ReconcilePaused()),
+				}
+			},
+			composer: ComposerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) (composedctrl.Observation, error) {
+				t.Errorf("Compose(...): a paused composite resource should not compose resources")
+				return composedctrl.Observation{}, nil
+			}),
+			deleter: ComposedDeleterFn(func(_ context.Context, _ resource.Composite) (bool, error) {
+				t.Errorf("DeleteComposed(...): a paused composite resource should not delete resources")
+				return false, nil
+			}),
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"AlreadyPaused": {
+			reason: "A paused composite resource that already reports it is paused should not be updated",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						cr := composite.New()
+						cr.SetAnnotations(map[string]string{AnnotationKeyPaused: "true"})
+						cr.SetConditions(v1alpha1.ReconcilePaused())
+						obj.(*kunstructured.Unstructured).Object = cr.Object
+						return nil
+					},
+					MockStatusUpdate: test.NewMockStatusUpdateFn(errors.New("unexpected status update")),
+				}
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"WaitingForOtherFinalizers": {
+			reason: "A composite resource should not delete its composed resources until its other finalizers are removed",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+						_ = beingDeleted(ctx, key, obj)
+						u := obj.(*kunstructured.Unstructured)
+						u.SetFinalizers(append(u.GetFinalizers(), "example.org/cleanup"))
+						return nil
+					},
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileSuccess()),
+				}
+			},
+			deleter: ComposedDeleterFn(func(_ context.Context, _ resource.Composite) (bool, error) {
+				t.Errorf("DeleteComposed(...): composed resources should not be deleted while other finalizers exist")
+				return false, nil
+			}),
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"WaitingForComposedResourceDeletion": {
+			reason: "A composite resource should wait for its composed resources to be deleted before it is finalized",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet:          beingDeleted,
+					MockStatusUpdate: withConditions(t, runtimev1alpha1.Deleting(), runtimev1alpha1.ReconcileSuccess()),
+				}
+			},
+			deleter: ComposedDeleterFn(func(_ context.Context, _ resource.Composite) (bool, error) { return false, nil }),
+			want: want{
+				r: reconcile.Result{RequeueAfter: shortWait},
+			},
+		},
+		"ComposedResourcesDeleted": {
+			reason: "A composite resource should be finalized once its composed resources are deleted",
+			client: func(t *testing.T) client.Client {
+				return &test.MockClient{
+					MockGet: beingDeleted,
+					MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						if f := obj.(*kunstructured.Unstructured).GetFinalizers(); len(f) != 0 {
+							t.Errorf("Update(): want no finalizers, got %v", f)
+						}
+						return nil
+					},
+				}
+			},
+			deleter: ComposedDeleterFn(func(_ context.Context, _ resource.Composite) (bool, error) { return true, nil }),
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []ReconcilerOption{
+				WithSelectorResolver(SelectorResolverFn(noop)),
+				WithConfigurator(ConfiguratorFn(noopConfigure)),
+			}
+			if tc.composer != nil {
+				opts = append(opts, WithComposer(tc.composer))
+			}
+			if tc.deleter != nil {
+				opts = append(opts, WithComposedDeleter(tc.deleter))
+			}
+			opts = append(opts, tc.opts...)
+			r := NewReconciler(&fake.Manager{Client: tc.client(t)}, kind, opts...)
+			got, err := r.Reconcile(reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRequeueJitter(t *testing.T) {
+	kind := resource.CompositeKind(schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XExample"})
+	max := 10 * time.Second
+
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *kunstructured.Unstructured:
+				cr := composite.New()
+				cr.SetCompositionReference(&corev1.ObjectReference{Name: "cool-composition"})
+				o.Object = cr.Object
+			case *v1alpha1.Composition:
+				*o = v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{AllowEmpty: true}}
+			}
+			return nil
+		},
+		MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+	}
+
+	var bound int64
+	rnd := func(n int64) int64 {
+		bound = n
+		return int64(7 * time.Second)
+	}
+
+	r := NewReconciler(&fake.Manager{Client: c}, kind,
+		WithSelectorResolver(SelectorResolverFn(func(_ context.Context, _ resource.Composite) error { return nil })),
+		WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1alpha1.Composition) error { return nil })),
+		WithRequeueJitter(max, rnd),
+	)
+	got, err := r.Reconcile(reconcile.Request{})
+	if err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	if diff := cmp.Diff(reconcile.Result{RequeueAfter: longWait + 7*time.Second}, got); diff != "" {
+		t.Errorf("r.Reconcile(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(int64(max)+1, bound); diff != "" {
+		t.Errorf("WithRequeueJitter(...): jitter should be bounded by the maximum: -want, +got:\n%s", diff)
+	}
+}
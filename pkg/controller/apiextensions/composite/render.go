@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	composedctrl "github.com/crossplane/crossplane/pkg/controller/apiextensions/composite/composed"
+)
+
+// Error strings.
+const (
+	errFmtRender = "cannot render composed resource at index %d"
+)
+
+// RenderComposite returns the resources that the supplied composite resource
+// would compose using the supplied composition, without reading from or
+// writing to an API server. Composed resources that the composite resource
+// already references are rendered using those references, so that they keep
+// their names. Composed resources whose template condition is not met are
+// omitted. The supplied ComposerOptions may be used to override how
+// composed resources are configured and overlaid.
+func RenderComposite(cp resource.Composite, comp *v1alpha1.Composition, o ...composedctrl.ComposerOption) ([]resource.Composed, error) {
+	c := composedctrl.NewComposer(nil, o...)
+
+	refs := make([]corev1.ObjectReference, len(comp.Spec.To))
+	copy(refs, cp.GetResourceReferences())
+
+	out := make([]resource.Composed, 0, len(comp.Spec.To))
+	for i, t := range comp.Spec.To {
+		if t.Condition != nil {
+			met, err := t.Condition.Met(cp)
+			if err != nil {
+				return nil, errors.Wrapf(err, errFmtRender, i)
+			}
+			if !met {
+				continue
+			}
+		}
+		cd := composed.New(composed.FromReference(refs[i]))
+		if err := c.Render(cp, cd, WithPropagatedLabels(t, comp.Spec.PropagateLabels)); err != nil {
+			return nil, errors.Wrapf(err, errFmtRender, i)
+		}
+		out = append(out, cd)
+	}
+	return out, nil
+}
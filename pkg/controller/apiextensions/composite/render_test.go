@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	composedctrl "github.com/crossplane/crossplane/pkg/controller/apiextensions/composite/composed"
+)
+
+func TestRenderComposite(t *testing.T) {
+	tmpl := v1alpha1.ComposedTemplate{
+		Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Composed","spec":{"size":"large"}}`)},
+		Patches: []v1alpha1.Patch{
+			{FromFieldPath: "metadata.labels.region", ToFieldPath: "spec.region"},
+		},
+	}
+	comp := &v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{To: []v1alpha1.ComposedTemplate{tmpl, tmpl}}}
+
+	type args struct {
+		cp   resource.Composite
+		comp *v1alpha1.Composition
+		o    []composedctrl.ComposerOption
+	}
+	type want struct {
+		rendered []map[string]interface{}
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Rendered": {
+			reason: "Each template should be rendered, keeping the names of any composed resources the composite already references",
+			args: args{
+				cp: func() resource.Composite {
+					cp := composite.New()
+					cp.SetName("cool")
+					cp.SetLabels(map[string]string{"region": "us-west"})
+					cp.SetResourceReferences([]corev1.ObjectReference{
+						{APIVersion: "example.org/v1", Kind: "Composed", Name: "cool-existing"},
+					})
+					return cp
+				}(),
+				comp: comp,
+			},
+			want: want{
+				rendered: []map[string]interface{}{
+					{
+						"apiVersion": "example.org/v1",
+						"kind":       "Composed",
+						"metadata":   map[string]interface{}{"name": "cool-existing", "generateName": "cool-"},
+						"spec":       map[string]interface{}{"size": "large", "region": "us-west"},
+					},
+					{
+						"apiVersion": "example.org/v1",
+						"kind":       "Composed",
+						"metadata":   map[string]interface{}{"generateName": "cool-"},
+						"spec":       map[string]interface{}{"size": "large", "region": "us-west"},
+					},
+				},
+			},
+		},
+		"RenderError": {
+			reason: "Errors rendering a template should be returned",
+			args: args{
+				cp:   &fake.Composite{},
+				comp: comp,
+				o: []composedctrl.ComposerOption{
+					composedctrl.WithOverlayApplicator(composedctrl.OverlayFn(func(_ resource.Composite, _ resource.Composed, _ v1alpha1.ComposedTemplate) error {
+						return errBoom
+					})),
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.Wrap(errBoom, "cannot apply overlay"), errFmtRender, 0),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenderComposite(tc.args.cp, tc.args.comp, tc.args.o...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRenderComposite(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			var rendered []map[string]interface{}
+			for _, cd := range got {
+				c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cd)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rendered = append(rendered, c)
+			}
+			if diff := cmp.Diff(tc.want.rendered, rendered); diff != "" {
+				t.Errorf("\n%s\nRenderComposite(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
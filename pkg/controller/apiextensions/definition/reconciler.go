@@ -18,7 +18,11 @@ package definition
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,12 +31,14 @@ import (
 	kmeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	kcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
@@ -66,12 +72,14 @@ const (
 	errDeleteCRD       = "cannot delete CustomResourceDefinition"
 	errListCRs         = "cannot list defined custom resources"
 	errDeleteCRs       = "cannot delete defined custom resources"
+	errListComps       = "cannot list Compositions"
 )
 
 // Wait strings.
 const (
 	waitCRDelete     = "waiting for defined custom resources to be deleted"
 	waitCRDEstablish = "waiting for CustomResourceDefinition to be established"
+	waitComposedKind = "waiting for composed resource kinds to be served"
 )
 
 // Event reasons.
@@ -105,16 +113,21 @@ func (fn CRDRenderFn) Render(d *v1alpha1.InfrastructureDefinition) (*v1beta1.Cus
 }
 
 // Setup adds a controller that reconciles ApplicationConfigurations.
-func Setup(mgr ctrl.Manager, log logging.Logger) error {
+func Setup(mgr ctrl.Manager, log logging.Logger, compositeJitter time.Duration, maxComposed int64) error {
 	name := "apiextensions/" + strings.ToLower(v1alpha1.InfrastructureDefinitionGroupKind)
 	r := NewReconciler(mgr,
 		WithLogger(log.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithCompositeReconcilerOptions(
+			composite.WithRequeueJitter(compositeJitter, rand.Int63n),
+			composite.WithMaxComposedResources(maxComposed),
+		))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha1.InfrastructureDefinition{}).
 		Owns(&v1beta1.CustomResourceDefinition{}).
+		Watches(&source.Kind{Type: &v1alpha1.Composition{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: DefinitionsForComposition(mgr.GetClient())}).
 		WithOptions(kcontroller.Options{MaxConcurrentReconciles: maxConcurrency}).
 		Complete(r)
 }
@@ -160,6 +173,15 @@ func WithCRDRenderer(c CRDRenderer) ReconcilerOption {
 	}
 }
 
+// WithCompositeReconcilerOptions specifies options that should be used to
+// configure the Reconcilers of the composite controllers that the Reconciler
+// starts.
+func WithCompositeReconcilerOptions(o ...composite.ReconcilerOption) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composite = append(r.composite, o...)
+	}
+}
+
 type definition struct {
 	CRDRenderer
 	ControllerEngine
@@ -193,6 +215,8 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 
 		log:    logging.NewNopLogger(),
 		record: event.NewNopRecorder(),
+
+		running: make(map[string]compositeController),
 	}
 
 	for _, f := range opts {
@@ -207,9 +231,74 @@ type Reconciler struct {
 	mgr    manager.Manager
 
 	definition definition
+	composite  []composite.ReconcilerOption
 
 	log    logging.Logger
 	record event.Recorder
+
+	// running tracks the composite controller started for each
+	// InfrastructureDefinition, by name.
+	running map[string]compositeController
+	starts  int
+	mx      sync.Mutex
+}
+
+// A compositeController is a composite controller that has been started.
+type compositeController struct {
+	// name is the name the controller was started with.
+	name string
+
+	// config is the configuration the controller was started with.
+	config compositeConfig
+}
+
+// A compositeConfig is the configuration of a composite controller that can
+// only be changed by restarting it.
+type compositeConfig struct {
+	// watch is the kinds of composed resource the controller watches.
+	watch []schema.GroupVersionKind
+
+	// maxComposed overrides the maximum number of resources the controller
+	// may compose for a composite resource, if non-nil.
+	maxComposed *int64
+}
+
+// configure records that the composite controller of the named
+// InfrastructureDefinition should run with the supplied configuration. It
+// returns the name the controller should be started with, and the name of a
+// previously started controller that must be stopped because it was started
+// with a different configuration, if any.
+func (r *Reconciler) configure(definition string, c compositeConfig) (start, stop string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	cc, ok := r.running[definition]
+	if ok && reflect.DeepEqual(cc.config, c) {
+		return cc.name, ""
+	}
+
+	// The controller engine forgets a controller when its goroutines exit,
+	// which happens some time after it is stopped. A restarted controller
+	// must not share the stopped controller's name, or the engine will
+	// forget (i.e. stop) it too.
+	r.starts++
+	r.running[definition] = compositeController{
+		name:   fmt.Sprintf("%s-%d", composite.ControllerName(definition), r.starts),
+		config: c,
+	}
+	return r.running[definition].name, cc.name
+}
+
+// stop the composite controller of the named InfrastructureDefinition, if it
+// was started, and forget it.
+func (r *Reconciler) stop(definition string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if cc, ok := r.running[definition]; ok {
+		r.definition.Stop(cc.name)
+		delete(r.running, definition)
+	}
 }
 
 // TODO(muvaf,negz): Consider deduplicating this Reconciler with the
@@ -274,7 +363,7 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 			// It's likely that we've already stopped this controller on a
 			// previous reconcile, but we try again just in case. This is a
 			// no-op if the controller was already stopped.
-			r.definition.Stop(composite.ControllerName(d.GetName()))
+			r.stop(d.GetName())
 
 			if err := r.definition.RemoveFinalizer(ctx, d); err != nil {
 				log.Debug(errRemoveFinalizer, "error", err)
@@ -323,7 +412,7 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 
 		// The controller should be stopped before the deletion of CRD so that
 		// it doesn't crash.
-		r.definition.Stop(composite.ControllerName(d.GetName()))
+		r.stop(d.GetName())
 
 		if err := r.client.Delete(ctx, crd); resource.IgnoreNotFound(err) != nil {
 			log.Debug(errDeleteCRD, "error", err)
@@ -362,17 +451,55 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return reconcile.Result{RequeueAfter: 3 * time.Second}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
 	}
 
-	o := kcontroller.Options{Reconciler: composite.NewReconciler(r.mgr,
-		resource.CompositeKind(d.GetDefinedGroupVersionKind()),
+	co := append([]composite.ReconcilerOption{
 		composite.WithConnectionPublisher(composite.NewAPIFilteredSecretPublisher(r.client, d.GetConnectionSecretKeys())),
 		composite.WithLogger(log.WithValues("controller", composite.ControllerName(d.GetName()))),
 		composite.WithRecorder(event.NewAPIRecorder(r.mgr.GetEventRecorderFor(composite.ControllerName(d.GetName())))),
-	)}
+	}, r.composite...)
+	if m := d.Spec.MaxComposedResources; m != nil {
+		co = append(co, composite.WithMaxComposedResources(*m))
+	}
+	o := kcontroller.Options{Reconciler: composite.NewReconciler(r.mgr, resource.CompositeKind(d.GetDefinedGroupVersionKind()), co...)}
 
 	u := &kunstructured.Unstructured{}
 	u.SetGroupVersionKind(d.GetDefinedGroupVersionKind())
 
-	if err := r.definition.Start(composite.ControllerName(d.GetName()), o, controller.For(u, &handler.EnqueueRequestForObject{})); err != nil {
+	comps := &v1alpha1.CompositionList{}
+	if err := r.client.List(ctx, comps); err != nil {
+		log.Debug(errListComps, "error", err)
+		r.record.Event(d, event.Warning(reasonApplyDef, err))
+		d.Status.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errListComps)))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	}
+
+	// We watch the resources our composite resources compose so that they're
+	// reconciled promptly when a composed resource changes. We can only watch
+	// kinds of resource that exist, and we must restart the controller to
+	// change what it watches. Kinds that don't exist yet, for example because
+	// the CRD that defines them is still being established, are watched once
+	// a later reconcile finds that they exist.
+	w := []controller.Watch{controller.For(u, &handler.EnqueueRequestForObject{})}
+	gvks := make([]schema.GroupVersionKind, 0)
+	unmapped := 0
+	for _, gvk := range ComposedGVKs(comps.Items, d.GetDefinedGroupVersionKind()) {
+		if _, err := r.mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			log.Debug("Cannot watch composed resource kind", "error", err, "kind", gvk.String())
+			unmapped++
+			continue
+		}
+		cd := &kunstructured.Unstructured{}
+		cd.SetGroupVersionKind(gvk)
+		w = append(w, controller.For(cd, &handler.EnqueueRequestForOwner{OwnerType: u, IsController: true}))
+		gvks = append(gvks, gvk)
+	}
+	// The controller must be restarted to change what it watches or how many
+	// resources it may compose.
+	name, stale := r.configure(d.GetName(), compositeConfig{watch: gvks, maxComposed: d.Spec.MaxComposedResources})
+	if stale != "" {
+		r.definition.Stop(stale)
+	}
+
+	if err := r.definition.Start(name, o, w...); err != nil {
 		log.Debug(errStartController, "error", err)
 		r.record.Event(d, event.Warning(reasonApplyDef, err))
 		d.Status.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(err, errStartController)))
@@ -382,5 +509,11 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 	d.Status.SetConditions(v1alpha1.Started())
 	d.Status.SetConditions(runtimev1alpha1.ReconcileSuccess())
 	r.record.Event(d, event.Normal(reasonApplyDef, "Applied CustomResourceDefinition and (re)started composite controller"))
+
+	if unmapped > 0 {
+		// Requeue until we can watch every kind of composed resource.
+		log.Debug(waitComposedKind, "unmapped", unmapped)
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	}
 	return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
 }
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+type mockManager struct {
+	fake.Manager
+}
+
+func (m *mockManager) GetEventRecorderFor(_ string) record.EventRecorder {
+	return record.NewFakeRecorder(100)
+}
+
+// A mockEngine counts how many times it stopped a running controller.
+type mockEngine struct {
+	running  bool
+	restarts int
+}
+
+func (e *mockEngine) IsRunning(_ string) bool {
+	return e.running
+}
+
+func (e *mockEngine) Start(_ string, _ kcontroller.Options, _ ...controller.Watch) error {
+	e.running = true
+	return nil
+}
+
+func (e *mockEngine) Stop(_ string) {
+	if e.running {
+		e.restarts++
+	}
+	e.running = false
+}
+
+// newTestReconciler returns a Reconciler of an InfrastructureDefinition named
+// cool, which is modified by the supplied function each time it is reconciled.
+// The InfrastructureDefinition's CRD is established, and there are no
+// Compositions, so there is nothing for its composite controller to watch.
+func newTestReconciler(e ControllerEngine, fn func(d *v1alpha1.InfrastructureDefinition)) *Reconciler {
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.InfrastructureDefinition:
+				o.SetName("cool")
+				fn(o)
+			case *v1beta1.CustomResourceDefinition:
+				o.Status.Conditions = []v1beta1.CustomResourceDefinitionCondition{{Type: v1beta1.Established, Status: v1beta1.ConditionTrue}}
+			}
+			return nil
+		},
+		MockList:         test.NewMockListFn(nil),
+		MockPatch:        test.NewMockPatchFn(nil),
+		MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+	}
+	return NewReconciler(&mockManager{Manager: fake.Manager{Client: c}},
+		WithControllerEngine(e),
+		WithFinalizer(resource.FinalizerFns{
+			AddFinalizerFn:    func(_ context.Context, _ resource.Object) error { return nil },
+			RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil },
+		}),
+		WithCRDRenderer(CRDRenderFn(func(_ *v1alpha1.InfrastructureDefinition) (*v1beta1.CustomResourceDefinition, error) {
+			return &v1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "cools.example.org"}}, nil
+		})),
+	)
+}
+
+func TestReconcileMaxComposedResources(t *testing.T) {
+	five, ten := int64(5), int64(10)
+
+	// The limit of the InfrastructureDefinition is changed while its composite
+	// controller is running.
+	var limit *int64
+	e := &mockEngine{}
+	r := newTestReconciler(e, func(d *v1alpha1.InfrastructureDefinition) { d.Spec.MaxComposedResources = limit })
+
+	cases := []struct {
+		reason string
+		limit  *int64
+		want   int
+	}{
+		{reason: "The composite controller should be started when it is not running", want: 0},
+		{reason: "The composite controller should keep running when its configuration is unchanged", want: 0},
+		{reason: "The composite controller should be restarted when a limit is set", limit: &five, want: 1},
+		{reason: "The composite controller should keep running when its limit is unchanged", limit: &five, want: 1},
+		{reason: "The composite controller should be restarted when its limit changes", limit: &ten, want: 2},
+		{reason: "The composite controller should be restarted when its limit is removed", want: 3},
+	}
+
+	for _, tc := range cases {
+		limit = tc.limit
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}); err != nil {
+			t.Fatalf("\n%s\nr.Reconcile(...): %s", tc.reason, err)
+		}
+		if !e.running {
+			t.Errorf("\n%s\nr.Reconcile(...): want composite controller running", tc.reason)
+		}
+		if diff := cmp.Diff(tc.want, e.restarts); diff != "" {
+			t.Errorf("\n%s\nr.Reconcile(...): -want restarts, +got restarts:\n%s", tc.reason, diff)
+		}
+	}
+}
+
+// A blockingCache runs until it is stopped.
+type blockingCache struct {
+	cache.Cache
+}
+
+func (c *blockingCache) Start(stop <-chan struct{}) error {
+	<-stop
+	return nil
+}
+
+// A blockingController runs until it is stopped, then reports its name.
+type blockingController struct {
+	kcontroller.Controller
+
+	name    string
+	stopped chan<- string
+}
+
+func (c *blockingController) Watch(_ source.Source, _ handler.EventHandler, _ ...predicate.Predicate) error {
+	return nil
+}
+
+func (c *blockingController) Start(stop <-chan struct{}) error {
+	<-stop
+	c.stopped <- c.name
+	return nil
+}
+
+func TestReconcileRestartWithEngine(t *testing.T) {
+	five := int64(5)
+
+	var limit *int64
+	stopped := make(chan string, 10)
+	e := controller.NewEngine(&mockManager{},
+		controller.WithNewCacheFn(func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+			return &blockingCache{}, nil
+		}),
+		controller.WithNewControllerFn(func(name string, _ manager.Manager, _ kcontroller.Options) (kcontroller.Controller, error) {
+			return &blockingController{name: name, stopped: stopped}, nil
+		}),
+	)
+	r := newTestReconciler(e, func(d *v1alpha1.InfrastructureDefinition) { d.Spec.MaxComposedResources = limit })
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	first := r.running["cool"].name
+
+	// Changing the limit restarts the composite controller.
+	limit = &five
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	second := r.running["cool"].name
+
+	select {
+	case name := <-stopped:
+		if diff := cmp.Diff(first, name); diff != "" {
+			t.Errorf("r.Reconcile(...): -want stopped controller, +got stopped controller:\n%s", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("r.Reconcile(...): composite controller %q was not stopped", first)
+	}
+
+	// The stopped controller's goroutines exiting must not stop the restarted
+	// controller.
+	select {
+	case name := <-stopped:
+		t.Errorf("r.Reconcile(...): restarted composite controller %q was stopped", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if !e.IsRunning(second) {
+		t.Errorf("r.Reconcile(...): want restarted composite controller %q running", second)
+	}
+}
+
+func TestReconcileDeleteForgetsController(t *testing.T) {
+	deleted := false
+	e := &mockEngine{}
+	r := newTestReconciler(e, func(d *v1alpha1.InfrastructureDefinition) {
+		if deleted {
+			now := metav1.Now()
+			d.SetDeletionTimestamp(&now)
+		}
+	})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+
+	// The CRD has no creation timestamp, so it appears to have been deleted.
+	deleted = true
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	if e.running {
+		t.Errorf("r.Reconcile(...): want composite controller stopped")
+	}
+	if _, ok := r.running["cool"]; ok {
+		t.Errorf("r.Reconcile(...): want composite controller of deleted definition forgotten")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// DefinitionsForComposition returns a function that maps a Composition to
+// requests for the InfrastructureDefinitions that define the kind of composite
+// resource it is compatible with. The composite controller of such an
+// InfrastructureDefinition may need to watch the kinds of resource that the
+// Composition composes.
+func DefinitionsForComposition(c client.Reader) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		comp, ok := o.Object.(*v1alpha1.Composition)
+		if !ok {
+			return nil
+		}
+
+		l := &v1alpha1.InfrastructureDefinitionList{}
+		if err := c.List(context.Background(), l); err != nil {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, d := range l.Items {
+			a, k := d.GetDefinedGroupVersionKind().ToAPIVersionAndKind()
+			if comp.Spec.From.APIVersion != a || comp.Spec.From.Kind != k {
+				continue
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: d.GetName()}})
+		}
+		return reqs
+	}
+}
+
+// ComposedGVKs returns the kinds of resource composed by those of the supplied
+// Compositions that are compatible with the supplied kind of composite
+// resource. Each kind is returned once, sorted by its string representation.
+func ComposedGVKs(comps []v1alpha1.Composition, of schema.GroupVersionKind) []schema.GroupVersionKind {
+	a, k := of.ToAPIVersionAndKind()
+
+	seen := map[schema.GroupVersionKind]bool{}
+	gvks := make([]schema.GroupVersionKind, 0)
+	for _, comp := range comps {
+		if comp.Spec.From.APIVersion != a || comp.Spec.From.Kind != k {
+			continue
+		}
+		for _, t := range comp.Spec.To {
+			u := &unstructured.Unstructured{}
+			if err := json.Unmarshal(t.Base.Raw, &u.Object); err != nil {
+				continue
+			}
+			gvk := u.GroupVersionKind()
+			if gvk.Kind == "" || seen[gvk] {
+				continue
+			}
+			seen[gvk] = true
+			gvks = append(gvks, gvk)
+		}
+	}
+
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+	return gvks
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestDefinitionsForComposition(t *testing.T) {
+	defined := func(name, group, kind string) v1alpha1.InfrastructureDefinition {
+		d := v1alpha1.InfrastructureDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		d.Spec.CRDSpecTemplate.Group = group
+		d.Spec.CRDSpecTemplate.Version = "v1alpha1"
+		d.Spec.CRDSpecTemplate.Names.Kind = kind
+		return d
+	}
+	c := &test.MockClient{
+		MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+			obj.(*v1alpha1.InfrastructureDefinitionList).Items = []v1alpha1.InfrastructureDefinition{
+				defined("xexamples.example.org", "example.org", "XExample"),
+				defined("xothers.example.org", "example.org", "XOther"),
+			}
+			return nil
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   []reconcile.Request
+	}{
+		"NotAComposition": {
+			reason: "Objects that are not Compositions should not be mapped to requests",
+			obj:    &v1alpha1.InfrastructureDefinition{},
+		},
+		"Compatible": {
+			reason: "A Composition should be mapped to the InfrastructureDefinition that defines the kind it is compatible with",
+			obj: &v1alpha1.Composition{Spec: v1alpha1.CompositionSpec{
+				From: v1alpha1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XExample"},
+			}},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "xexamples.example.org"}}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DefinitionsForComposition(c)(handler.MapObject{Object: tc.obj})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDefinitionsForComposition(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestComposedGVKs(t *testing.T) {
+	of := schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XExample"}
+	tmpl := func(base string) v1alpha1.ComposedTemplate {
+		return v1alpha1.ComposedTemplate{Base: runtime.RawExtension{Raw: []byte(base)}}
+	}
+
+	comps := []v1alpha1.Composition{
+		{Spec: v1alpha1.CompositionSpec{
+			From: v1alpha1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XExample"},
+			To: []v1alpha1.ComposedTemplate{
+				tmpl(`{"apiVersion":"example.org/v1","kind":"Subnet"}`),
+				tmpl(`{"apiVersion":"example.org/v1","kind":"Instance"}`),
+			},
+		}},
+		{Spec: v1alpha1.CompositionSpec{
+			From: v1alpha1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XExample"},
+			To: []v1alpha1.ComposedTemplate{
+				tmpl(`{"apiVersion":"example.org/v1","kind":"Instance"}`),
+				tmpl(`not json`),
+			},
+		}},
+		{Spec: v1alpha1.CompositionSpec{
+			From: v1alpha1.TypeReference{APIVersion: "example.org/v1alpha1", Kind: "XOther"},
+			To: []v1alpha1.ComposedTemplate{
+				tmpl(`{"apiVersion":"example.org/v1","kind":"Bucket"}`),
+			},
+		}},
+	}
+
+	want := []schema.GroupVersionKind{
+		{Group: "example.org", Version: "v1", Kind: "Instance"},
+		{Group: "example.org", Version: "v1", Kind: "Subnet"},
+	}
+	if diff := cmp.Diff(want, ComposedGVKs(comps, of)); diff != "" {
+		t.Errorf("ComposedGVKs(...): -want, +got:\n%s", diff)
+	}
+}
@@ -20,7 +20,10 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
@@ -28,6 +31,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/requirement"
 )
 
 // Error strings.
@@ -37,6 +41,27 @@ const (
 	errUpdateComposite   = "cannot update composite resource"
 	errDeleteComposite   = "cannot delete composite resource"
 	errBindConflict      = "cannot bind composite resource that references a different requirement"
+
+	errGetSecret            = "cannot get composite resource's connection secret"
+	errSecretConflict       = "cannot establish control of existing connection secret"
+	errUpdateSecret         = "cannot update connection secret"
+	errCreateOrUpdateSecret = "cannot create or update connection secret"
+	errFmtKeyConflict       = "cannot write connection secret keys %q and %q to the same key %q"
+)
+
+// A CompositeDeletePolicy determines what happens to a composite resource when
+// the requirement that it is bound to is deleted.
+type CompositeDeletePolicy string
+
+// Composite delete policies.
+const (
+	// CompositeDeleteBackground deletes the composite resource if its reclaim
+	// policy is "Delete".
+	CompositeDeleteBackground CompositeDeletePolicy = "Background"
+
+	// CompositeDeleteOrphan unbinds, but never deletes, the composite
+	// resource.
+	CompositeDeleteOrphan CompositeDeletePolicy = "Orphan"
 )
 
 // An APICompositeCreator creates resources by submitting them to a Kubernetes
@@ -109,14 +134,19 @@ func (a *APIBinder) Bind(ctx context.Context, rq resource.Requirement, cp resour
 
 // Unbind the supplied Requirement from the supplied Composite resource by
 // removing the composite resource's requirement reference, and if the composite
-// resource's reclaim policy is "Delete", deleting it.
-func (a *APIBinder) Unbind(ctx context.Context, _ resource.Requirement, cp resource.Composite) error {
+// resource's reclaim policy is "Delete", deleting it. The composite resource is
+// never deleted if the requirement's composite delete policy is "Orphan".
+func (a *APIBinder) Unbind(ctx context.Context, rq resource.Requirement, cp resource.Composite) error {
 	RemoveRequirementReference(cp)
 
 	if err := a.client.Update(ctx, cp); err != nil {
 		return errors.Wrap(resource.IgnoreNotFound(err), errUpdateComposite)
 	}
 
+	if GetCompositeDeletePolicy(rq) == CompositeDeleteOrphan {
+		return nil
+	}
+
 	// We go to the trouble of unbinding the composite resource before deleting it
 	// because we want it to show up as "released" (not "bound") if its composite
 	// resource reconciler is wedged or delayed trying to delete it.
@@ -127,6 +157,135 @@ func (a *APIBinder) Unbind(ctx context.Context, _ resource.Requirement, cp resou
 	return errors.Wrap(resource.IgnoreNotFound(a.client.Delete(ctx, cp)), errDeleteComposite)
 }
 
+// An APIConnectionPropagator propagates connection details by reading them
+// from a composite resource's connection secret and writing them to its
+// requirement's connection secret in a Kubernetes API server. Keys are renamed
+// per the requirement's connection secret key map, if any.
+type APIConnectionPropagator struct {
+	client resource.ClientApplicator
+	typer  runtime.ObjectTyper
+}
+
+// NewAPIConnectionPropagator returns a new APIConnectionPropagator.
+func NewAPIConnectionPropagator(c client.Client, t runtime.ObjectTyper) *APIConnectionPropagator {
+	return &APIConnectionPropagator{
+		client: resource.ClientApplicator{Client: c, Applicator: resource.NewAPIUpdatingApplicator(c)},
+		typer:  t,
+	}
+}
+
+// PropagateConnection details from the supplied composite resource to the
+// supplied requirement.
+func (a *APIConnectionPropagator) PropagateConnection(ctx context.Context, to resource.LocalConnectionSecretOwner, from resource.ConnectionSecretOwner) error {
+	// Either from does not expose a connection secret, or to does not want one.
+	if from.GetWriteConnectionSecretToReference() == nil || to.GetWriteConnectionSecretToReference() == nil {
+		return nil
+	}
+
+	n := types.NamespacedName{
+		Namespace: from.GetWriteConnectionSecretToReference().Namespace,
+		Name:      from.GetWriteConnectionSecretToReference().Name,
+	}
+	fs := &corev1.Secret{}
+	if err := a.client.Get(ctx, n, fs); err != nil {
+		return errors.Wrap(err, errGetSecret)
+	}
+
+	// Make sure the composite resource is the controller of the connection
+	// secret it references before we propagate it. This ensures a composite
+	// resource cannot use Crossplane to circumvent RBAC by propagating a secret
+	// it does not own.
+	if c := metav1.GetControllerOf(fs); c == nil || c.UID != from.GetUID() {
+		return errors.New(errSecretConflict)
+	}
+
+	data, err := RemapConnectionDetails(fs.Data, GetConnectionSecretKeyMap(to))
+	if err != nil {
+		return err
+	}
+
+	ts := resource.LocalConnectionSecretFor(to, resource.MustGetKind(to, a.typer))
+	ts.Data = data
+
+	meta.AllowPropagation(fs, ts)
+
+	if err := a.client.Apply(ctx, ts, resource.ConnectionSecretMustBeControllableBy(to.GetUID())); err != nil {
+		return errors.Wrap(err, errCreateOrUpdateSecret)
+	}
+
+	return errors.Wrap(a.client.Update(ctx, fs), errUpdateSecret)
+}
+
+// RemapConnectionDetails renames the keys of the supplied connection details
+// per the supplied map of existing to new key names. Keys that are not in the
+// map are passed through unchanged. Two keys may not be written to the same
+// key, whether they are renamed or passed through.
+func RemapConnectionDetails(data map[string][]byte, m map[string]string) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(data))
+	from := make(map[string]string, len(data))
+	for k, v := range data {
+		to := k
+		if r, ok := m[k]; ok {
+			to = r
+		}
+		if existing, ok := from[to]; ok {
+			// Map iteration order is random; report the conflicting keys in
+			// a stable order.
+			a, b := existing, k
+			if b < a {
+				a, b = b, a
+			}
+			return nil, errors.Errorf(errFmtKeyConflict, a, b, to)
+		}
+		from[to] = k
+		out[to] = v
+	}
+	return out, nil
+}
+
+// GetConnectionSecretKeyMap returns the connection secret key map of the
+// supplied resource.LocalConnectionSecretOwner if it contains a
+// *requirement.Unstructured. The map renames the keys of the composite
+// resource's connection secret when they are propagated to the requirement.
+func GetConnectionSecretKeyMap(o resource.LocalConnectionSecretOwner) map[string]string {
+	urq, ok := o.(*requirement.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	// TODO(negz): Make this a constant in the ccrd package?
+	i, _ := fieldpath.Pave(urq.Object).GetValue("spec.writeConnectionSecretToRef.keyMap")
+	km, ok := i.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	m := make(map[string]string, len(km))
+	for k, v := range km {
+		if s, ok := v.(string); ok {
+			m[k] = s
+		}
+	}
+	return m
+}
+
+// GetCompositeDeletePolicy returns the composite delete policy of the supplied
+// resource.Requirement if it contains a *requirement.Unstructured. The
+// CompositeDeleteBackground policy is returned if no policy is set.
+func GetCompositeDeletePolicy(rq resource.Requirement) CompositeDeletePolicy {
+	urq, ok := rq.(*requirement.Unstructured)
+	if !ok {
+		return CompositeDeleteBackground
+	}
+
+	// TODO(negz): Make this a constant in the ccrd package?
+	p, _ := fieldpath.Pave(urq.Object).GetString("spec.compositeDeletePolicy")
+	if p == "" {
+		return CompositeDeleteBackground
+	}
+	return CompositeDeletePolicy(p)
+}
+
 // RemoveRequirementReference removes the requirement reference from the
 // supplied resource.Composite if it contains a *composite.Unstructured.
 func RemoveRequirementReference(cp resource.Composite) {
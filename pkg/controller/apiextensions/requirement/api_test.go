@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requirement
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/requirement"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRemapConnectionDetails(t *testing.T) {
+	type args struct {
+		data map[string][]byte
+		m    map[string]string
+	}
+	type want struct {
+		data map[string][]byte
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Passthrough": {
+			reason: "Keys should be passed through unchanged when there is no key map",
+			args: args{
+				data: map[string][]byte{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+			},
+			want: want{
+				data: map[string][]byte{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+			},
+		},
+		"Remap": {
+			reason: "Keys in the key map should be renamed, and other keys passed through",
+			args: args{
+				data: map[string][]byte{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+				m:    map[string]string{"endpoint": "DB_HOST", "username": "DB_USER"},
+			},
+			want: want{
+				data: map[string][]byte{"DB_HOST": []byte("db.example.org"), "port": []byte("5432")},
+			},
+		},
+		"Swap": {
+			reason: "Keys should be able to swap names",
+			args: args{
+				data: map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+				m:    map[string]string{"a": "b", "b": "a"},
+			},
+			want: want{
+				data: map[string][]byte{"a": []byte("2"), "b": []byte("1")},
+			},
+		},
+		"ConflictingRenames": {
+			reason: "Two keys should not be renamed to the same key",
+			args: args{
+				data: map[string][]byte{"endpoint": []byte("db.example.org"), "address": []byte("10.0.0.1")},
+				m:    map[string]string{"endpoint": "DB_HOST", "address": "DB_HOST"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtKeyConflict, "address", "endpoint", "DB_HOST"),
+			},
+		},
+		"RenameConflictsWithPassthrough": {
+			reason: "A key should not be renamed to a key that is passed through",
+			args: args{
+				data: map[string][]byte{"endpoint": []byte("db.example.org"), "host": []byte("10.0.0.1")},
+				m:    map[string]string{"endpoint": "host"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtKeyConflict, "endpoint", "host", "host"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RemapConnectionDetails(tc.args.data, tc.args.m)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRemapConnectionDetails(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nRemapConnectionDetails(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetConnectionSecretKeyMap(t *testing.T) {
+	rq := requirement.New()
+	rq.Object["spec"] = map[string]interface{}{
+		"writeConnectionSecretToRef": map[string]interface{}{
+			"name":   "cool",
+			"keyMap": map[string]interface{}{"endpoint": "DB_HOST"},
+		},
+	}
+
+	want := map[string]string{"endpoint": "DB_HOST"}
+	if diff := cmp.Diff(want, GetConnectionSecretKeyMap(rq)); diff != "" {
+		t.Errorf("GetConnectionSecretKeyMap(...): -want, +got:\n%s", diff)
+	}
+}
@@ -60,7 +60,7 @@ func Configure(_ context.Context, rq resource.Requirement, cp resource.Composite
 	}
 
 	// TODO(negz): Make these filtered keys constants in the ccrds package?
-	_ = fieldpath.Pave(ucp.Object).SetValue("spec", filter(spec, "resourceRef", "writeConnectionSecretToRef"))
+	_ = fieldpath.Pave(ucp.Object).SetValue("spec", filter(spec, "resourceRef", "writeConnectionSecretToRef", "compositeDeletePolicy"))
 	return nil
 }
 
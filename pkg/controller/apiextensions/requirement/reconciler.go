@@ -159,7 +159,7 @@ func defaultCRComposite(c client.Client, t runtime.ObjectTyper) crComposite {
 	return crComposite{
 		CompositeConfigurator: CompositeConfiguratorFn(Configure),
 		CompositeCreator:      NewAPICompositeCreator(c, t),
-		ConnectionPropagator:  resource.NewAPIConnectionPropagator(c, t),
+		ConnectionPropagator:  NewAPIConnectionPropagator(c, t),
 	}
 }
 
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// A RevisionObject identifies an object output by a package install job.
+type RevisionObject struct {
+	schema.GroupVersionKind
+	Namespace string
+	Name      string
+}
+
+// A RevisionDiff describes how the objects output by one revision of a package
+// differ from those output by another.
+type RevisionDiff struct {
+	// Added objects are output by the new revision but not the old.
+	Added []RevisionObject
+
+	// Removed objects are output by the old revision but not the new.
+	Removed []RevisionObject
+
+	// Changed objects are output by both revisions, but differ.
+	Changed []RevisionObject
+}
+
+// RemovedCRDs returns the CRDs that were removed. Removing a CRD deletes all
+// of its custom resources, so these deserve particular attention.
+func (d RevisionDiff) RemovedCRDs() []RevisionObject {
+	crds := make([]RevisionObject, 0)
+	for _, o := range d.Removed {
+		if o.Group == "apiextensions.k8s.io" && o.Kind == "CustomResourceDefinition" {
+			crds = append(crds, o)
+		}
+	}
+	return crds
+}
+
+// DiffRevisions returns the objects that were added, removed, and changed
+// going from the supplied objects of an old revision to those of a new
+// revision. Objects are identified by their GroupVersionKind, namespace, and
+// name. Objects that can't be identified are ignored. Status and
+// server-populated metadata are ignored when determining whether an object
+// changed.
+func DiffRevisions(from, to []runtime.Object) RevisionDiff {
+	o, n := revisionObjects(from), revisionObjects(to)
+
+	d := RevisionDiff{
+		Added:   make([]RevisionObject, 0),
+		Removed: make([]RevisionObject, 0),
+		Changed: make([]RevisionObject, 0),
+	}
+	for id, oc := range o {
+		nc, ok := n[id]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, id)
+		case !equality.Semantic.DeepEqual(oc, nc):
+			d.Changed = append(d.Changed, id)
+		}
+	}
+	for id := range n {
+		if _, ok := o[id]; !ok {
+			d.Added = append(d.Added, id)
+		}
+	}
+
+	sortRevisionObjects(d.Added)
+	sortRevisionObjects(d.Removed)
+	sortRevisionObjects(d.Changed)
+	return d
+}
+
+// revisionObjects returns the comparable content of the supplied objects,
+// keyed by their identity.
+func revisionObjects(objs []runtime.Object) map[RevisionObject]map[string]interface{} {
+	out := make(map[RevisionObject]map[string]interface{}, len(objs))
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		a, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		id := RevisionObject{GroupVersionKind: obj.GetObjectKind().GroupVersionKind(), Namespace: a.GetNamespace(), Name: a.GetName()}
+
+		var c map[string]interface{}
+		if u, ok := obj.(runtime.Unstructured); ok {
+			c = runtime.DeepCopyJSON(u.UnstructuredContent())
+		} else if c, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			continue
+		}
+		out[id] = declared(c)
+	}
+	return out
+}
+
+// declared strips the supplied object content of the fields that don't
+// reflect what a package declared.
+func declared(c map[string]interface{}) map[string]interface{} {
+	delete(c, "status")
+	m, ok := c["metadata"].(map[string]interface{})
+	if !ok {
+		return c
+	}
+	kept := map[string]interface{}{}
+	for _, f := range []string{"labels", "annotations"} {
+		if v, ok := m[f]; ok {
+			kept[f] = v
+		}
+	}
+	c["metadata"] = kept
+	return c
+}
+
+func sortRevisionObjects(objs []RevisionObject) {
+	sort.Slice(objs, func(i, j int) bool {
+		a, b := objs[i], objs[j]
+		if a.GroupVersionKind != b.GroupVersionKind {
+			return a.GroupVersionKind.String() < b.GroupVersionKind.String()
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	crdGVK := apiextensions.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+
+	// typedCRD returns a typed CRD, which lacks type metadata unless we set it.
+	typedCRD := func(group, kind string, cm ...crdModifier) runtime.Object {
+		c := crd(append([]crdModifier{withCRDGroupKind(group, kind)}, cm...)...)
+		c.SetGroupVersionKind(crdGVK)
+		return &c
+	}
+
+	type args struct {
+		from []runtime.Object
+		to   []runtime.Object
+	}
+
+	type want struct {
+		diff        RevisionDiff
+		removedCRDs []RevisionObject
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoChanges": {
+			reason: "Identical revisions should have no differences.",
+			args: args{
+				from: []runtime.Object{unstructuredObj(crdRaw)},
+				to:   []runtime.Object{unstructuredObj(crdRaw)},
+			},
+			want: want{
+				diff:        RevisionDiff{Added: []RevisionObject{}, Removed: []RevisionObject{}, Changed: []RevisionObject{}},
+				removedCRDs: []RevisionObject{},
+			},
+		},
+		"AddedRemovedAndChanged": {
+			reason: "Objects should be reported as added, removed, or changed by GVK and name.",
+			args: args{
+				from: []runtime.Object{
+					typedCRD("example.org", "Removed"),
+					typedCRD("example.org", "Changed"),
+					typedCRD("example.org", "Unchanged"),
+				},
+				to: []runtime.Object{
+					typedCRD("example.org", "Added"),
+					typedCRD("example.org", "Changed", withCRDVersion("v1beta1")),
+					// Status is not declared by a package, so this is not a change.
+					typedCRD("example.org", "Unchanged", withCRDCondition(apiextensions.Established, "")),
+				},
+			},
+			want: want{
+				diff: RevisionDiff{
+					Added:   []RevisionObject{{GroupVersionKind: crdGVK, Name: "addeds.example.org"}},
+					Removed: []RevisionObject{{GroupVersionKind: crdGVK, Name: "removeds.example.org"}},
+					Changed: []RevisionObject{{GroupVersionKind: crdGVK, Name: "changeds.example.org"}},
+				},
+				removedCRDs: []RevisionObject{{GroupVersionKind: crdGVK, Name: "removeds.example.org"}},
+			},
+		},
+		"RemovedPackage": {
+			reason: "Removed objects that are not CRDs should not be reported as removed CRDs.",
+			args: args{
+				from: []runtime.Object{unstructuredObj(packageRaw(packageEnvelopeImage))},
+			},
+			want: want{
+				diff: RevisionDiff{
+					Added: []RevisionObject{},
+					Removed: []RevisionObject{{
+						GroupVersionKind: schema.GroupVersionKind{Group: "packages.crossplane.io", Version: "v1alpha1", Kind: "Package"},
+					}},
+					Changed: []RevisionObject{},
+				},
+				removedCRDs: []RevisionObject{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DiffRevisions(tc.args.from, tc.args.to)
+			if diff := cmp.Diff(tc.want.diff, got); diff != "" {
+				t.Errorf("\n%s\nDiffRevisions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.removedCRDs, got.RemovedCRDs()); diff != "" {
+				t.Errorf("\n%s\nRemovedCRDs(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
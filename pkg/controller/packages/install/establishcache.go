@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"sync"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+// A cacheKey identifies a CRD established for a particular PackageInstaller.
+type cacheKey struct {
+	installer types.UID
+	crd       string
+}
+
+// An establishedCRD is a CRD that was previously established.
+type establishedCRD struct {
+	resourceVersion string
+	desired         map[string]interface{}
+}
+
+// A CachingEstablisher remembers the resource version of each CRD it has
+// established for each PackageInstaller, so that a CRD that is still at that
+// version need not be created or updated again when the PackageInstaller's
+// install job output is next handled. CRDs may be shared by many packages, so a
+// CRD established for one PackageInstaller is not up to date for another. It
+// keeps no copy of the CRDs themselves; it reads them using the client that
+// establishes them, which is typically backed by the controller manager's
+// informer cache. Objects other than CRDs are always established.
+type CachingEstablisher struct {
+	mu  sync.RWMutex
+	crd map[cacheKey]establishedCRD
+}
+
+// NewCachingEstablisher returns a CachingEstablisher that has not yet
+// established any CRDs.
+func NewCachingEstablisher() *CachingEstablisher {
+	return &CachingEstablisher{crd: map[cacheKey]establishedCRD{}}
+}
+
+// WithCachingEstablisher specifies a CachingEstablisher that should be used to
+// skip establishing CRDs that have not changed since they were last
+// established.
+func WithCachingEstablisher(ce *CachingEstablisher) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.decorators = append(jc.decorators, func(e objectEstablisher) objectEstablisher {
+			return &cachingObjectEstablisher{cache: ce, client: jc.client, log: jc.log, wrapped: e}
+		})
+	}
+}
+
+// A cachingObjectEstablisher decorates an objectEstablisher, skipping CRDs
+// that its CachingEstablisher knows to be up to date.
+type cachingObjectEstablisher struct {
+	cache   *CachingEstablisher
+	client  client.Reader
+	log     logging.Logger
+	wrapped objectEstablisher
+}
+
+func (e *cachingObjectEstablisher) establishObject(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
+	if !isCRD(obj) {
+		return e.wrapped.establishObject(ctx, obj, i, job)
+	}
+
+	if e.cache.UpToDate(ctx, e.client, i, obj) {
+		e.log.Debug("crd is unchanged since it was last established", "gvk", obj.GroupVersionKind().String(), "name", obj.GetName(), "job", job.Name, "action", "none")
+		return outcomeUpdated, nil
+	}
+
+	// The wrapped objectEstablisher may modify the CRD.
+	desired := obj.DeepCopy()
+	o, err := e.wrapped.establishObject(ctx, obj, i, job)
+
+	// A skipped CRD was never established, so it must be considered again
+	// next time rather than cached.
+	if err != nil || o == outcomeSkipped {
+		e.cache.Forget(i, obj.GetName())
+		return o, err
+	}
+
+	e.cache.Established(ctx, e.client, i, desired)
+	return o, nil
+}
+
+// UpToDate returns true if the supplied desired CRD is identical to the one
+// that was last established for the supplied PackageInstaller, and the supplied
+// client reads the CRD at the resource version it had when it was last
+// established.
+func (ce *CachingEstablisher) UpToDate(ctx context.Context, c client.Reader, i metav1.Object, desired *unstructured.Unstructured) bool {
+	ce.mu.RLock()
+	e, ok := ce.crd[cacheKey{installer: i.GetUID(), crd: desired.GetName()}]
+	ce.mu.RUnlock()
+	if !ok || !equality.Semantic.DeepEqual(e.desired, desired.Object) {
+		return false
+	}
+
+	existing := &apiextensions.CustomResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: desired.GetName()}, existing); err != nil {
+		return false
+	}
+	return existing.GetResourceVersion() == e.resourceVersion
+}
+
+// Established records that the supplied desired CRD was established for the
+// supplied PackageInstaller. The desired CRD must be recorded before it is
+// passed to a client, which may modify it.
+func (ce *CachingEstablisher) Established(ctx context.Context, c client.Reader, i metav1.Object, desired *unstructured.Unstructured) {
+	existing := &apiextensions.CustomResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: desired.GetName()}, existing); err != nil {
+		// We'll establish this CRD again next time; no harm done.
+		ce.Forget(i, desired.GetName())
+		return
+	}
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	ce.crd[cacheKey{installer: i.GetUID(), crd: desired.GetName()}] = establishedCRD{resourceVersion: existing.GetResourceVersion(), desired: desired.Object}
+}
+
+// Forget the CRD with the supplied name that was established for the supplied
+// PackageInstaller, so that it will be established again.
+func (ce *CachingEstablisher) Forget(i metav1.Object, name string) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	delete(ce.crd, cacheKey{installer: i.GetUID(), crd: name})
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+func TestCachingEstablisher(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		installer *v1alpha1.PackageInstall
+		desired   *unstructured.Unstructured
+		modify    func(ctx context.Context, c client.Client) error
+		want      want
+	}{
+		"UnchangedCRD": {
+			reason:  "A CRD that is unchanged since it was last established should not be established again.",
+			desired: unstructuredObj(crdRaw),
+			modify:  func(_ context.Context, _ client.Client) error { return nil },
+		},
+		"ChangedDesiredCRD": {
+			reason:  "A CRD whose desired state changed since it was last established should be established again.",
+			desired: unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("new"))),
+			modify:  func(_ context.Context, _ client.Client) error { return nil },
+			want: want{
+				err: errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, "cool-packageinstall"),
+			},
+		},
+		"DifferentInstaller": {
+			reason: "A CRD that was last established for a different PackageInstaller should be established again.",
+			installer: func() *v1alpha1.PackageInstall {
+				i := packageInstallResource()
+				i.SetUID("another-uuid")
+				return i
+			}(),
+			desired: unstructuredObj(crdRaw),
+			modify:  func(_ context.Context, _ client.Client) error { return nil },
+			want: want{
+				err: errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, "cool-packageinstall"),
+			},
+		},
+		"ChangedExistingCRD": {
+			reason:  "A CRD whose resource version changed since it was last established should be established again.",
+			desired: unstructuredObj(crdRaw),
+			modify: func(ctx context.Context, c client.Client) error {
+				crd := &apiextensions.CustomResourceDefinition{}
+				if err := c.Get(ctx, types.NamespacedName{Name: crdName}, crd); err != nil {
+					return err
+				}
+				crd.SetLabels(map[string]string{"cool": "label"})
+				return c.Update(ctx, crd)
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, "cool-packageinstall"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			fc := fake.NewFakeClient()
+			ce := NewCachingEstablisher()
+
+			jc := &packageInstallJobCompleter{client: fc, log: logging.NewNopLogger()}
+			WithCachingEstablisher(ce)(jc)
+			if _, err := jc.createJobOutputObject(ctx, unstructuredObj(crdRaw), packageInstallResource(), job()); err != nil {
+				t.Fatalf("createJobOutputObject(...): %s", err)
+			}
+
+			if err := tc.modify(ctx, fc); err != nil {
+				t.Fatalf("modify(...): %s", err)
+			}
+
+			// Establishing the CRD again will fail unless it is skipped.
+			jc.client = &test.MockClient{
+				MockGet:    fc.Get,
+				MockCreate: test.NewMockCreateFn(errBoom),
+			}
+			i := packageInstallResource()
+			if tc.installer != nil {
+				i = tc.installer
+			}
+			_, err := jc.createJobOutputObject(ctx, tc.desired, i, job())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncreateJobOutputObject(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCachingEstablisherSkipped(t *testing.T) {
+	// The existing CRD was not installed by the package manager.
+	existing := crd(withCRDGroupKind("samples.upbound.io", "Mytype"))
+	existing.SetResourceVersion("1")
+	fc := fake.NewFakeClient(&existing)
+
+	jc := &packageInstallJobCompleter{client: fc, log: logging.NewNopLogger(), conflicts: ConflictSkip}
+	WithCachingEstablisher(NewCachingEstablisher())(jc)
+
+	// A skipped CRD should still be skipped when it is established again,
+	// rather than being treated as established because it was cached.
+	for i := 0; i < 2; i++ {
+		o, err := jc.createJobOutputObject(context.Background(), unstructuredObj(crdRaw), packageInstallResource(), job())
+		if err != nil {
+			t.Fatalf("createJobOutputObject(...): %s", err)
+		}
+		if diff := cmp.Diff(outcomeSkipped, o); diff != "" {
+			t.Errorf("createJobOutputObject(...): attempt %d: -want outcome, +got outcome:\n%s", i+1, diff)
+		}
+	}
+}
+
+// An objectEstablisherFn is an objectEstablisher implemented as a function.
+type objectEstablisherFn func(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error)
+
+func (fn objectEstablisherFn) establishObject(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
+	return fn(ctx, obj, i, job)
+}
+
+func TestCachingObjectEstablisher(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		obj    *unstructured.Unstructured
+		want   int
+	}{
+		"CRD": {
+			reason: "An unchanged CRD should only be passed to the wrapped establisher once.",
+			obj:    unstructuredObj(crdRaw),
+			want:   1,
+		},
+		"NotCRD": {
+			reason: "Objects other than CRDs should always be passed to the wrapped establisher.",
+			obj: func() *unstructured.Unstructured {
+				u := unstructuredObj(packageRaw("crossplane/sample-package:latest"))
+				u.SetName(resourceName)
+				u.SetNamespace(namespace)
+				return u
+			}(),
+			want: 2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fc := fake.NewFakeClient()
+			calls := 0
+			wrapped := objectEstablisherFn(func(ctx context.Context, obj *unstructured.Unstructured, _ v1alpha1.PackageInstaller, _ *batchv1.Job) (establishOutcome, error) {
+				calls++
+				if err := fc.Create(ctx, obj); err != nil && !kerrors.IsAlreadyExists(err) {
+					return outcomeSkipped, err
+				}
+				return outcomeCreated, nil
+			})
+
+			jc := &packageInstallJobCompleter{client: fc, log: logging.NewNopLogger()}
+			WithCachingEstablisher(NewCachingEstablisher())(jc)
+			e := jc.decorators[0](wrapped)
+
+			for attempt := 1; attempt <= 2; attempt++ {
+				// The wrapped establisher may modify the object, so we pass a copy.
+				if _, err := e.establishObject(context.Background(), tc.obj.DeepCopy(), packageInstallResource(), job()); err != nil {
+					t.Fatalf("establishObject(...): attempt %d: %s", attempt, err)
+				}
+			}
+			if diff := cmp.Diff(tc.want, calls); diff != "" {
+				t.Errorf("\n%s\nestablishObject(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+// HealthCheckLastEstablish is the name of the health check that reports
+// whether package objects are being established.
+const HealthCheckLastEstablish = "last-establish"
+
+// An EstablishTracker tracks the package installs whose objects each
+// controller is failing to establish.
+type EstablishTracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	failing map[establishKey]failure
+}
+
+type establishKey struct {
+	controller string
+	install    string
+}
+
+// A failure records when establishing the objects of a package install first
+// and last failed, since they were last established.
+type failure struct {
+	first time.Time
+	last  time.Time
+}
+
+// NewEstablishTracker returns an EstablishTracker whose health check fails if
+// a controller has been failing to establish the objects of a package install
+// for longer than the supplied window.
+func NewEstablishTracker(window time.Duration) *EstablishTracker {
+	return &EstablishTracker{
+		window:  window,
+		now:     time.Now,
+		failing: map[establishKey]failure{},
+	}
+}
+
+// Established records that the supplied controller successfully established
+// the objects of the supplied package install.
+func (t *EstablishTracker) Established(controller, install string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failing, establishKey{controller: controller, install: install})
+}
+
+// Failed records that the supplied controller failed to establish the objects
+// of the supplied package install.
+func (t *EstablishTracker) Failed(controller, install string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := establishKey{controller: controller, install: install}
+	f, ok := t.failing[k]
+	if !ok {
+		f.first = t.now()
+	}
+	f.last = t.now()
+	t.failing[k] = f
+}
+
+// Check returns an error if a controller has been failing to establish the
+// objects of a package install for longer than the tracker's window. A package
+// install whose establishment has not been retried within the window, for
+// example because it was deleted, is ignored. The package manager is thus
+// considered healthy when it has no package objects to establish. Check
+// satisfies healthz.Checker.
+func (t *EstablishTracker) Check(_ *http.Request) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := t.now()
+	stalled := make([]string, 0)
+	for k, f := range t.failing {
+		if now.Sub(f.first) <= t.window || now.Sub(f.last) > t.window {
+			continue
+		}
+		stalled = append(stalled, k.controller+" "+k.install+" since "+f.first.Format(time.RFC3339))
+	}
+
+	if len(stalled) == 0 {
+		return nil
+	}
+	sort.Strings(stalled)
+	return errors.Errorf("failing to establish package objects for longer than %s: %s", t.window, strings.Join(stalled, ", "))
+}
+
+// WithEstablishTracker specifies an EstablishTracker that should be told each
+// time the supplied controller establishes, or fails to establish, all of the
+// objects output by a package install job. Failures caused by a package whose
+// objects can never be established are not reported, because they don't
+// indicate a problem with the package manager.
+func WithEstablishTracker(t *EstablishTracker, controller string) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.established = func(i v1alpha1.PackageInstaller, err error) {
+			install := types.NamespacedName{Namespace: i.GetNamespace(), Name: i.GetName()}.String()
+			switch {
+			case err == nil:
+				t.Established(controller, install)
+			case retryable(err):
+				t.Failed(controller, install)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// An establishEvent is a report that establishing the objects of a package install
+// succeeded or failed.
+type establishEvent struct {
+	controller string
+	install    string
+	at         time.Time
+	failed     bool
+}
+
+func TestEstablishTrackerCheck(t *testing.T) {
+	started := time.Date(2020, 04, 01, 0, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+
+	tests := []struct {
+		name   string
+		events []establishEvent
+		now    time.Time
+		want   error
+	}{
+		{
+			name: "Idle",
+			now:  started.Add(24 * time.Hour),
+		},
+		{
+			name: "RecentlyEstablished",
+			events: []establishEvent{
+				{controller: "a", install: "ns/a", at: started.Add(2 * time.Minute)},
+			},
+			now: started.Add(20 * time.Minute),
+		},
+		{
+			name: "RecentlyFailing",
+			events: []establishEvent{
+				{controller: "a", install: "ns/a", at: started.Add(15 * time.Minute), failed: true},
+				{controller: "a", install: "ns/a", at: started.Add(18 * time.Minute), failed: true},
+			},
+			now: started.Add(20 * time.Minute),
+		},
+		{
+			name: "RecoveredFromFailure",
+			events: []establishEvent{
+				{controller: "a", install: "ns/a", at: started.Add(1 * time.Minute), failed: true},
+				{controller: "a", install: "ns/a", at: started.Add(18 * time.Minute), failed: true},
+				{controller: "a", install: "ns/a", at: started.Add(19 * time.Minute)},
+			},
+			now: started.Add(20 * time.Minute),
+		},
+		{
+			name: "FailureNoLongerRetried",
+			events: []establishEvent{
+				{controller: "a", install: "ns/a", at: started.Add(1 * time.Minute), failed: true},
+				{controller: "a", install: "ns/a", at: started.Add(5 * time.Minute), failed: true},
+			},
+			now: started.Add(20 * time.Minute),
+		},
+		{
+			name: "EstablishmentStalled",
+			events: []establishEvent{
+				{controller: "b", install: "ns/b", at: started.Add(5 * time.Minute), failed: true},
+				{controller: "a", install: "ns/a", at: started.Add(2 * time.Minute), failed: true},
+				{controller: "c", install: "ns/c", at: started.Add(3 * time.Minute), failed: true},
+				{controller: "c", install: "ns/c", at: started.Add(4 * time.Minute)},
+				{controller: "a", install: "ns/a", at: started.Add(25 * time.Minute), failed: true},
+				{controller: "b", install: "ns/b", at: started.Add(28 * time.Minute), failed: true},
+			},
+			now: started.Add(30 * time.Minute),
+			want: errors.Errorf("failing to establish package objects for longer than %s: %s", window,
+				"a ns/a since "+started.Add(2*time.Minute).Format(time.RFC3339)+", "+
+					"b ns/b since "+started.Add(5*time.Minute).Format(time.RFC3339)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			et := NewEstablishTracker(window)
+			for _, e := range tt.events {
+				e := e
+				et.now = func() time.Time { return e.at }
+				if e.failed {
+					et.Failed(e.controller, e.install)
+					continue
+				}
+				et.Established(e.controller, e.install)
+			}
+			et.now = func() time.Time { return tt.now }
+
+			if diff := cmp.Diff(tt.want, et.Check(nil), test.EquateErrors()); diff != "" {
+				t.Errorf("Check(): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithEstablishTracker(t *testing.T) {
+	at := time.Date(2020, 04, 01, 0, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+
+	tests := []struct {
+		name   string
+		reason string
+		err    error
+		want   error
+	}{
+		{
+			name:   "RetryableFailure",
+			reason: "A retryable failure to establish package objects should be tracked.",
+			err:    errors.New("boom"),
+			want: errors.Errorf("failing to establish package objects for longer than %s: %s", window,
+				"packageinstall "+namespace+"/"+resourceName+" since "+at.Format(time.RFC3339)),
+		},
+		{
+			name:   "PermanentFailure",
+			reason: "A failure caused by the package should not be tracked.",
+			err:    permanent(errors.New("boom")),
+		},
+		{
+			name:   "Success",
+			reason: "Establishing package objects should clear any tracked failure.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			et := NewEstablishTracker(window)
+			et.now = func() time.Time { return at }
+
+			jc := &packageInstallJobCompleter{}
+			WithEstablishTracker(et, "packageinstall")(jc)
+
+			i := packageInstallResource()
+			jc.established(i, errors.New("previous failure"))
+
+			et.now = func() time.Time { return at.Add(window + time.Minute) }
+			jc.established(i, tt.err)
+
+			if diff := cmp.Diff(tt.want, et.Check(nil), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheck(): -want error, +got error:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
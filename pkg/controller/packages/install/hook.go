@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	runtimeresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+const (
+	preActivationHookSuffix    = "-pre-activation"
+	preActivationHookContainer = "pre-activation-hook"
+
+	errFmtHookFailed   = "pre-activation hook job failed: %s"
+	errFmtStaleHookJob = "stale job %s/%s prevents pre-activation hook"
+)
+
+// preActivationHookJob returns the Job that runs the supplied pre-activation
+// hook for the supplied install job. It runs alongside the install job, and
+// shares its labels so that it is cleaned up with the install job. It pulls its
+// image using the install job's image pull secrets.
+func preActivationHookJob(hook *v1alpha1.PreActivationHook, installJob *batchv1.Job) *batchv1.Job {
+	backoff := int32(0)
+	if hook.BackoffLimit != nil {
+		backoff = *hook.BackoffLimit
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        installJob.GetName() + preActivationHookSuffix,
+			Namespace:   installJob.GetNamespace(),
+			Labels:      installJob.GetLabels(),
+			Annotations: installJob.GetAnnotations(),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoff,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: installJob.Spec.Template.Spec.ImagePullSecrets,
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    preActivationHookContainer,
+						Image:   hook.Image,
+						Command: hook.Command,
+						Args:    hook.Args,
+						Env:     hook.Env,
+					}},
+				},
+			},
+		},
+	}
+}
+
+// awaitPreActivationHook creates the pre-activation hook job of the supplied
+// install job if it does not yet exist. It returns true once the hook job has
+// completed successfully, and an error if it failed.
+func (h *packageInstallHandler) awaitPreActivationHook(ctx context.Context, hook *v1alpha1.PreActivationHook, installJob *batchv1.Job) (bool, error) {
+	want := preActivationHookJob(hook, installJob)
+
+	job := &batchv1.Job{}
+	err := h.hostKube.Get(ctx, types.NamespacedName{Name: want.GetName(), Namespace: want.GetNamespace()}, job)
+	if kerrors.IsNotFound(err) {
+		h.debugWithName("creating pre-activation hook job", "job", want.GetName())
+		return false, h.hostKube.Create(ctx, want)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// A matching job was found. Unless the labels conflict, it's ours.
+	if labels.Conflicts(job.GetLabels(), want.GetLabels()) {
+		return false, errors.Errorf(errFmtStaleHookJob, job.GetNamespace(), job.GetName())
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, errors.Errorf(errFmtHookFailed, c.Message)
+		}
+	}
+
+	return false, nil
+}
+
+// deletePreActivationHook deletes the pre-activation hook job of the supplied
+// install job, if any.
+func (h *packageInstallHandler) deletePreActivationHook(ctx context.Context, installJob *batchv1.Job) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:      installJob.GetName() + preActivationHookSuffix,
+		Namespace: installJob.GetNamespace(),
+	}}
+	return runtimeresource.IgnoreNotFound(h.hostKube.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
@@ -22,21 +22,28 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apiextensionsinternal "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -44,12 +51,45 @@ import (
 	"github.com/crossplane/crossplane/pkg/packages"
 )
 
+const (
+	errStorageVersionChange = "failed due to replacement crd changing storage version of existing crd"
+	errCRDGroupNotAllowed   = "crd group is not owned by the package"
+	errCRDNotManaged        = "existing crd is not managed by the package manager"
+	errFmtCRDControlled     = "existing crd is controlled by %s"
+
+	reasonDeprecatedCRD event.Reason = "ConvertDeprecatedCRD"
+	reasonAdoptCRD      event.Reason = "AdoptCRD"
+)
+
 var (
 	jobBackoff                = int32(0)
 	registryDirName           = "/.registry"
 	packageContentsVolumeName = "package-contents"
 )
 
+// A permanentError is an error that retrying is unlikely to resolve, for
+// example because a package declared an object that can never be established.
+type permanentError struct{ error }
+
+// permanent marks the supplied error as one that retrying is unlikely to
+// resolve.
+func permanent(err error) error {
+	return permanentError{err}
+}
+
+// retryable returns true unless the supplied error, or the error that caused
+// it, is permanent. Errors returned by the API server because an object is
+// invalid are permanent.
+func retryable(err error) bool {
+	switch c := errors.Cause(err); {
+	case kerrors.IsInvalid(c), kerrors.IsBadRequest(c):
+		return false
+	default:
+		_, ok := c.(permanentError)
+		return !ok
+	}
+}
+
 // JobCompleter is an interface for handling job completion
 type jobCompleter interface {
 	handleJobCompletion(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error
@@ -61,6 +101,297 @@ type packageInstallJobCompleter struct {
 	hostClient   client.Client
 	podLogReader Reader
 	log          logging.Logger
+	record       event.Recorder
+
+	// immutableFields are the dot-separated field paths, by kind, that are
+	// set when an object is first created but never updated afterwards.
+	immutableFields map[schema.GroupKind][]string
+
+	// progress is called each time an object is handled with the
+	// PackageInstaller whose objects are being established, the number of
+	// objects established so far, and the total number of objects.
+	progress func(i v1alpha1.PackageInstaller, done, total int)
+
+	// established is called each time establishing the objects output by a
+	// job succeeds or fails.
+	established func(i v1alpha1.PackageInstaller, err error)
+
+	// order is the order in which objects are established, by kind. Objects
+	// of kinds that are not in the order are established last.
+	order []schema.GroupKind
+
+	// decorators wrap the objectEstablisher that establishes each object
+	// output by a job, in order.
+	decorators []func(objectEstablisher) objectEstablisher
+
+	// timeout, if non-zero, bounds how long establishing each object may
+	// take.
+	timeout time.Duration
+
+	// legacyCRDs determines how apiextensions.k8s.io/v1beta1 CRDs are
+	// established.
+	legacyCRDs LegacyCRDPolicy
+
+	// validateCRDGroups determines whether CRDs must be in an API group
+	// owned by the package.
+	validateCRDGroups bool
+
+	// validatePackage determines whether the package and the objects it
+	// outputs are validated before any are established.
+	validatePackage bool
+
+	// conflicts determines how existing CRDs that are not managed by the
+	// package manager are established.
+	conflicts ConflictStrategy
+
+	// tracer, if set, traces the establishment of objects.
+	tracer Tracer
+
+	// propagateAnnotations are the keys of the annotations that are copied
+	// from the PackageInstaller to each object it establishes.
+	propagateAnnotations []string
+
+	// filter, if set, limits establishment to objects of these kinds.
+	filter []schema.GroupKind
+
+	// cooldown, if non-zero, is the minimum time between attempts to
+	// establish a package's objects after a transient failure.
+	cooldown time.Duration
+
+	// mergePrinterColumns determines whether the additional printer columns
+	// of existing CRDs are merged with, rather than replaced by, those of
+	// the CRDs a package outputs.
+	mergePrinterColumns bool
+
+	// forceImagePullPolicy and defaultImagePullPolicy determine the image
+	// pull policy of a package's controller, as they do for its install job.
+	forceImagePullPolicy   string
+	defaultImagePullPolicy string
+}
+
+// A ConflictStrategy determines how a CRD output by a package install job is
+// established when a CRD of the same name already exists but is not managed by
+// the package manager.
+type ConflictStrategy string
+
+// Conflict strategies.
+const (
+	// ConflictAdopt adopts the existing CRD, even if it is controlled by
+	// something else.
+	ConflictAdopt ConflictStrategy = "Adopt"
+
+	// ConflictSkip leaves the existing CRD untouched. The package is not
+	// considered established while any of its objects are skipped.
+	ConflictSkip ConflictStrategy = "Skip"
+
+	// ConflictFail refuses to establish the package.
+	ConflictFail ConflictStrategy = "Fail"
+)
+
+// A LegacyCRDPolicy determines how apiextensions.k8s.io/v1beta1 CRDs output by
+// a package install job are established.
+type LegacyCRDPolicy string
+
+// Legacy CRD policies. There is deliberately no policy that rejects legacy
+// CRDs; package unpacking currently outputs only apiextensions.k8s.io/v1beta1
+// CRDs, so rejecting them would prevent any package that declares CRDs from
+// being established.
+const (
+	// LegacyCRDEstablish establishes legacy CRDs as they are.
+	LegacyCRDEstablish LegacyCRDPolicy = "Establish"
+
+	// LegacyCRDConvert converts legacy CRDs to apiextensions.k8s.io/v1
+	// CRDs before they are established, and records a warning event on the
+	// PackageInstaller.
+	LegacyCRDConvert LegacyCRDPolicy = "Convert"
+)
+
+// DefaultEstablishOrder establishes CRDs before any other objects, which may
+// depend on them.
+var DefaultEstablishOrder = []schema.GroupKind{
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+}
+
+// A JobCompleterOption configures how the objects output by a package install
+// job are established.
+type JobCompleterOption func(*packageInstallJobCompleter)
+
+// WithEstablishClient specifies the client used to establish the objects output
+// by a package install job, for example to establish them in a different API
+// server from the one the PackageInstall lives in. Established objects are
+// tied to their PackageInstall by labels rather than owner references, and
+// cleaned up by the PackageInstall's finalizer, so this works across clusters.
+func WithEstablishClient(c client.Client) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.client = c
+	}
+}
+
+// An APIEstablisher establishes the objects output by package install jobs in
+// an API server.
+type APIEstablisher struct {
+	jc *packageInstallJobCompleter
+}
+
+// NewAPIEstablisherForClient returns an APIEstablisher that establishes objects
+// using the supplied client, which may be for a different API server from the
+// one the PackageInstallers live in. Options are applied in order, so a later
+// WithEstablishClient option overrides the supplied client.
+func NewAPIEstablisherForClient(c client.Client, opts ...JobCompleterOption) *APIEstablisher {
+	jc := &packageInstallJobCompleter{client: c, log: logging.NewNopLogger(), record: event.NewNopRecorder()}
+	for _, o := range opts {
+		o(jc)
+	}
+	return &APIEstablisher{jc: jc}
+}
+
+// Establish the supplied objects, which were output by the supplied install job
+// of the supplied PackageInstaller, and record the outcome in the
+// PackageInstaller's status. Objects the job output previously that it no
+// longer outputs are pruned.
+func (e *APIEstablisher) Establish(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job, objs []*unstructured.Unstructured) error {
+	return e.jc.establishJobOutput(ctx, i, job, objs)
+}
+
+// WithEventRecorder specifies the recorder used to record events about the
+// PackageInstallers whose objects are established.
+func WithEventRecorder(r event.Recorder) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.record = r
+	}
+}
+
+// WithImmutableFields specifies dot-separated field paths, by kind, that are
+// set when an object is created but are never overwritten when an existing
+// object is updated. This allows operators to tune fields of established
+// objects (e.g. spec.preserveUnknownFields of a CRD) without the package
+// manager reverting them.
+func WithImmutableFields(f map[schema.GroupKind][]string) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.immutableFields = f
+	}
+}
+
+// WithEstablishOrder specifies the order in which objects output by a package
+// install job are established, by kind. Objects of kinds that are not in the
+// order are established last, in the order they were output.
+func WithEstablishOrder(gks ...schema.GroupKind) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.order = gks
+	}
+}
+
+// WithProgress specifies a function that is called with the PackageInstaller
+// whose objects are being established, the number of objects established so
+// far, and the total number of objects each time an object output by a package
+// install job is handled.
+func WithProgress(fn func(i v1alpha1.PackageInstaller, done, total int)) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.progress = fn
+	}
+}
+
+// WithPerObjectTimeout specifies how long establishing each object output by a
+// package install job may take. An object that takes too long to establish
+// fails fast, rather than consuming the rest of the reconcile's deadline.
+func WithPerObjectTimeout(d time.Duration) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.timeout = d
+	}
+}
+
+// WithLegacyCRDPolicy specifies how apiextensions.k8s.io/v1beta1 CRDs output
+// by a package install job are established. Without this option they are
+// established as they are.
+func WithLegacyCRDPolicy(p LegacyCRDPolicy) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.legacyCRDs = p
+	}
+}
+
+// WithCRDGroupValidation specifies that the objects output by a package install
+// job may only include CRDs in the API groups of the CRDs the package declares
+// it owns. A job that outputs any other CRD is rejected before any of its
+// objects are established.
+func WithCRDGroupValidation() JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.validateCRDGroups = true
+	}
+}
+
+// imagePullPolicy returns the pull policy of the images used to unpack and run
+// the supplied package. A forced policy overrides the one the package
+// specifies, which overrides the default policy.
+func imagePullPolicy(i v1alpha1.PackageInstaller, force, def string) corev1.PullPolicy {
+	if force != "" {
+		return corev1.PullPolicy(force)
+	}
+	if p := i.GetImagePullPolicy(); p != "" {
+		return p
+	}
+	return corev1.PullPolicy(def)
+}
+
+// WithPackageValidation specifies that the metadata of a package and the
+// objects output by its install job are checked for common problems, such as
+// duplicate CRDs, before any of its objects are established. A package that
+// fails validation is reported with a Validated condition and rejected.
+func WithPackageValidation() JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.validatePackage = true
+	}
+}
+
+// WithConflictStrategy specifies how a CRD output by a package install job is
+// established when a CRD of the same name already exists but is not managed by
+// the package manager. Without this option such a CRD is adopted unless it is
+// controlled by something else, in which case the package is not established.
+func WithConflictStrategy(s ConflictStrategy) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.conflicts = s
+	}
+}
+
+// WithAnnotationPropagation specifies the keys of the annotations that are
+// copied from a PackageInstaller to each object output by its install job when
+// they are established, for example to record their provenance. Propagated
+// annotations take precedence over those of existing objects.
+func WithAnnotationPropagation(keys ...string) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.propagateAnnotations = keys
+	}
+}
+
+// WithEstablishFilter specifies that only the objects output by a package
+// install job that are of the supplied kinds are established, for example to
+// establish a package's CRDs but not its other objects while troubleshooting. Objects of other kinds are neither established nor counted.
+// Without this option all objects are established.
+func WithEstablishFilter(gks ...schema.GroupKind) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.filter = gks
+	}
+}
+
+// WithEstablishCooldown specifies the minimum time to wait before trying again
+// to establish the objects output by a package install job after a transient
+// failure to establish them, regardless of how often the package install is
+// reconciled. This prevents a flaky API server from causing a tight loop. The
+// time of the next attempt is recorded in the package install's status.
+func WithEstablishCooldown(d time.Duration) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.cooldown = d
+	}
+}
+
+// WithPrinterColumnMerge specifies that when an existing CRD is updated, any of
+// its additional printer columns that the CRD output by a package install job
+// lacks are kept, for example because an operator added them for their
+// dashboards. Columns are identified by name; those the package outputs are
+// always present, and take precedence over existing columns of the same name.
+func WithPrinterColumnMerge() JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.mergePrinterColumns = true
+	}
 }
 
 type buildInstallJobParams struct {
@@ -75,6 +406,51 @@ type buildInstallJobParams struct {
 	labels                   map[string]string
 	annotations              map[string]string
 	imagePullSecrets         []corev1.LocalObjectReference
+
+	// packageSHA256 is the checksum a package tarball must have, if any.
+	packageSHA256 string
+
+	// resources are the resource requirements of the job's containers.
+	resources corev1.ResourceRequirements
+}
+
+// packageContentsContainer returns the init container that copies the
+// contents of a package into the package contents volume. A package image
+// contains its contents, while a package tarball is fetched from its URL by the
+// package manager.
+func packageContentsContainer(p buildInstallJobParams) corev1.Container {
+	c := corev1.Container{
+		Name:            "package-copy-to-volume",
+		Image:           p.img,
+		ImagePullPolicy: p.imagePullPolicy,
+		Command:         []string{"cp", "-R", registryDirName, "/ext-pkg/"},
+		Resources:       *p.resources.DeepCopy(),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      packageContentsVolumeName,
+				MountPath: "/ext-pkg",
+			},
+		},
+	}
+
+	if !packages.IsTarballURL(p.img) {
+		return c
+	}
+
+	c.Name = "package-fetch-to-volume"
+	c.Image = p.packageManagerImage
+	c.ImagePullPolicy = p.packageManagerPullPolicy
+	c.Command = nil
+	c.Args = []string{
+		"package",
+		"fetch",
+		"--url=" + p.img,
+		"--dir=/ext-pkg",
+	}
+	if p.packageSHA256 != "" {
+		c.Args = append(c.Args, "--sha256="+p.packageSHA256)
+	}
+	return c
 }
 
 func buildInstallJob(p buildInstallJobParams) *batchv1.Job {
@@ -91,25 +467,13 @@ func buildInstallJob(p buildInstallJobParams) *batchv1.Job {
 				Spec: corev1.PodSpec{
 					ImagePullSecrets: p.imagePullSecrets,
 					RestartPolicy:    corev1.RestartPolicyNever,
-					InitContainers: []corev1.Container{
-						{
-							Name:            "package-copy-to-volume",
-							Image:           p.img,
-							ImagePullPolicy: p.imagePullPolicy,
-							Command:         []string{"cp", "-R", registryDirName, "/ext-pkg/"},
-							VolumeMounts: []corev1.VolumeMount{
-								{
-									Name:      packageContentsVolumeName,
-									MountPath: "/ext-pkg",
-								},
-							},
-						},
-					},
+					InitContainers:   []corev1.Container{packageContentsContainer(p)},
 					Containers: []corev1.Container{
 						{
 							Name:            "package-unpack-and-output",
 							Image:           p.packageManagerImage,
 							ImagePullPolicy: p.packageManagerPullPolicy,
+							Resources:       *p.resources.DeepCopy(),
 							// "--debug" can be added to this list of Args to get debug output from the job,
 							// but note that will be included in the stdout from the pod, which makes it
 							// impossible to create the resources that the job unpacks.
@@ -161,7 +525,9 @@ func (jc *packageInstallJobCompleter) handleJobCompletion(ctx context.Context, i
 		return err
 	}
 
-	// decode and process all resources from job output
+	// decode all resources from job output before we create any, so that we
+	// know how many objects the package declared
+	objs := make([]*unstructured.Unstructured, 0)
 	d := yaml.NewYAMLOrJSONDecoder(b, 4096)
 	for {
 		obj := &unstructured.Unstructured{}
@@ -170,18 +536,270 @@ func (jc *packageInstallJobCompleter) handleJobCompletion(ctx context.Context, i
 				// we reached the end of the job output
 				break
 			}
-			return errors.Wrapf(err, "failed to parse output from job %s", job.Name)
+			return errors.Wrapf(permanent(err), "failed to parse output from job %s", job.Name)
+		}
+		if obj == nil {
+			continue
 		}
+		objs = append(objs, obj)
+	}
 
-		// process and create the object that we just decoded
-		if err := jc.createJobOutputObject(ctx, obj, i, job); err != nil {
-			return err
+	return jc.establishJobOutput(ctx, i, job, objs)
+}
+
+// establishJobOutput establishes the supplied objects output by the supplied
+// install job of the supplied PackageInstaller, then prunes any it established
+// previously that are no longer output.
+func (jc *packageInstallJobCompleter) establishJobOutput(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job, objs []*unstructured.Unstructured) error {
+	if jc.validateCRDGroups {
+		if err := validateCRDGroups(objs); err != nil {
+			return errors.Wrapf(err, "invalid output from job %s", job.Name)
+		}
+	}
+
+	if jc.validatePackage {
+		if err := validatePackage(i, objs); err != nil {
+			return errors.Wrapf(err, "invalid output from job %s", job.Name)
 		}
 	}
 
+	// Objects that rely on a capability the package does not declare are
+	// counted, but never established.
+	filtered := filterForEstablishment(objs, jc.filter)
+	supported := jc.filterForCapabilities(filtered, packageMetadata(objs))
+	i.SetConditions(v1alpha1.EstablishProgress(0, len(filtered)))
+	established, adopted, err := jc.establishAll(ctx, supported, i, job)
+	i.SetObjectCounts(established, len(filtered))
+	if err != nil {
+		// The counts and progress are persisted along with the error, so
+		// that progress is visible when establishment fails part way
+		// through, unless a more specific reason was already recorded.
+		if i.GetCondition(v1alpha1.TypeEstablished).Reason == v1alpha1.ReasonEstablishing {
+			i.SetConditions(v1alpha1.EstablishProgress(established, len(filtered)))
+		}
+		jc.reportEstablished(i, err)
+		return err
+	}
+	i.SetAdoptedCount(adopted)
+
+	// We prune using all objects the package declared, not only those we
+	// established, so that objects the filter leaves out are never deleted.
+	// Pruning identifies the declared objects by the name and namespace they
+	// would be established with, even if they were not established.
+	if err := jc.pruneStaleObjects(ctx, i, objs); err != nil {
+		jc.reportEstablished(i, err)
+		return err
+	}
+	jc.reportEstablished(i, nil)
+
+	// A skipped object isn't an error, but it does mean the API server may
+	// not reflect what the package declared, so we surface it.
+	if established != len(filtered) {
+		jc.log.Debug("established object count does not match declared count", "job", job.Name, "declared", len(filtered), "established", established)
+		i.SetConditions(v1alpha1.ObjectCountMismatch(len(filtered), established))
+		return nil
+	}
+	i.SetConditions(v1alpha1.Established(len(filtered)))
+
 	return nil
 }
 
+// reportEstablished reports whether establishing the objects output by the
+// install job of the supplied PackageInstaller succeeded.
+func (jc *packageInstallJobCompleter) reportEstablished(i v1alpha1.PackageInstaller, err error) {
+	if jc.established != nil {
+		jc.established(i, err)
+	}
+}
+
+// filterForEstablishment returns those of the supplied objects that are of the
+// supplied kinds, in order. All objects are returned if no kinds are supplied.
+func filterForEstablishment(objs []*unstructured.Unstructured, gks []schema.GroupKind) []*unstructured.Unstructured {
+	if len(gks) == 0 {
+		return objs
+	}
+
+	include := make(map[schema.GroupKind]bool, len(gks))
+	for _, gk := range gks {
+		include[gk] = true
+	}
+
+	filtered := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, o := range objs {
+		if include[o.GroupVersionKind().GroupKind()] {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered
+}
+
+// packageMetadata returns the metadata of the package that output the supplied
+// objects, or empty metadata if it cannot be determined.
+func packageMetadata(objs []*unstructured.Unstructured) *v1alpha1.AppMetadataSpec {
+	meta, err := PackageMetadata(objs)
+	if err != nil {
+		return &v1alpha1.AppMetadataSpec{}
+	}
+	return meta
+}
+
+// requiredCapability returns the package capability the supplied object relies
+// on, if any.
+func requiredCapability(o *unstructured.Unstructured) string {
+	if isCRD(o) {
+		if s, _, _ := unstructured.NestedString(o.Object, "spec", "conversion", "strategy"); s == string(apiextensions.WebhookConverter) {
+			return v1alpha1.CapabilityConversionWebhooks
+		}
+	}
+	return ""
+}
+
+// filterForCapabilities returns those of the supplied objects that do not rely
+// on a capability the supplied package metadata does not declare, in order.
+func (jc *packageInstallJobCompleter) filterForCapabilities(objs []*unstructured.Unstructured, meta *v1alpha1.AppMetadataSpec) []*unstructured.Unstructured {
+	supported := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, o := range objs {
+		if c := requiredCapability(o); c != "" && !meta.HasCapability(c) {
+			jc.objectLogger(o).Debug("not establishing object that relies on an undeclared package capability", "capability", c)
+			continue
+		}
+		supported = append(supported, o)
+	}
+	return supported
+}
+
+// validateCRDGroups returns an error if any of the supplied CRDs is in an API
+// group that is not owned by the supplied packages or stack definitions.
+func validateCRDGroups(objs []*unstructured.Unstructured) error {
+	allowed := map[string]bool{}
+	for _, o := range objs {
+		if !isPackageObject(o) && !isStackDefinitionObject(o) {
+			continue
+		}
+		crds, _, _ := unstructured.NestedSlice(o.Object, "spec", "customresourcedefinitions")
+		for _, c := range crds {
+			m, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			av, _ := m["apiVersion"].(string)
+			gv, err := schema.ParseGroupVersion(av)
+			if err != nil {
+				continue
+			}
+			allowed[gv.Group] = true
+		}
+	}
+
+	for _, o := range objs {
+		if !isCRD(o) {
+			continue
+		}
+		g, _, _ := unstructured.NestedString(o.Object, "spec", "group")
+		if !allowed[g] {
+			return permanent(errors.Errorf("%s: crd %s is in group %q", errCRDGroupNotAllowed, o.GetName(), g))
+		}
+	}
+	return nil
+}
+
+// validatePackage validates the supplied objects, and the metadata of the
+// package that output them, setting the supplied PackageInstaller's Validated
+// condition accordingly. It returns a permanent error if validation fails.
+func validatePackage(i v1alpha1.PackageInstaller, objs []*unstructured.Unstructured) error {
+	meta, err := PackageMetadata(objs)
+	errs := ValidatePackage(objs, meta)
+	if err != nil {
+		errs = append([]error{err}, errs...)
+	}
+	if len(errs) > 0 {
+		err := joinErrors(errs)
+		i.SetConditions(v1alpha1.Invalid(err.Error()))
+		return permanent(err)
+	}
+	i.SetConditions(v1alpha1.Validated())
+	return nil
+}
+
+// sortForEstablishment sorts the supplied objects by the position of their
+// kind in the supplied order. Objects of the same kind, or of kinds that are
+// not in the order, keep their relative positions.
+func sortForEstablishment(objs []*unstructured.Unstructured, order []schema.GroupKind) {
+	priority := func(o *unstructured.Unstructured) int {
+		gk := o.GroupVersionKind().GroupKind()
+		for i := range order {
+			if order[i] == gk {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(objs, func(i, j int) bool { return priority(objs[i]) < priority(objs[j]) })
+}
+
+// pruneStaleObjects deletes any Package or StackDefinition labeled as belonging
+// to the supplied PackageInstaller that is not among the supplied objects, for
+// example because it was output by a prior version of the package. The
+// supplied objects need not have been established. CRDs may be
+// shared by many packages, so they are left to deleteOrphanedCRDs.
+func (jc *packageInstallJobCompleter) pruneStaleObjects(ctx context.Context, i v1alpha1.PackageInstaller, objs []*unstructured.Unstructured) error {
+	current := map[types.NamespacedName]bool{}
+	for _, obj := range objs {
+		if isPackageObject(obj) || isStackDefinitionObject(obj) {
+			current[packageObjectName(obj, i)] = true
+		}
+	}
+
+	labels := client.MatchingLabels(packages.ParentLabels(i))
+	ns := client.InNamespace(i.GetNamespace())
+
+	sdList := &v1alpha1.StackDefinitionList{}
+	if err := jc.client.List(ctx, sdList, labels, ns); err != nil {
+		return errors.Wrap(err, "failed to list stack definitions to prune")
+	}
+	stale := make([]resource.Object, 0)
+	for idx := range sdList.Items {
+		if !current[types.NamespacedName{Namespace: sdList.Items[idx].GetNamespace(), Name: sdList.Items[idx].GetName()}] {
+			stale = append(stale, &sdList.Items[idx])
+		}
+	}
+
+	pkgList := &v1alpha1.PackageList{}
+	if err := jc.client.List(ctx, pkgList, labels, ns); err != nil {
+		return errors.Wrap(err, "failed to list packages to prune")
+	}
+	for idx := range pkgList.Items {
+		if !current[types.NamespacedName{Namespace: pkgList.Items[idx].GetNamespace(), Name: pkgList.Items[idx].GetName()}] {
+			stale = append(stale, &pkgList.Items[idx])
+		}
+	}
+
+	for _, o := range stale {
+		jc.log.Debug("pruning stale object", "name", o.GetName(), "namespace", o.GetNamespace(), "action", "delete")
+		if err := jc.client.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, "failed to prune stale object")
+		}
+	}
+
+	return nil
+}
+
+// packageObjectName returns the namespace and name with which the supplied
+// Package or StackDefinition output by the install job of the supplied
+// PackageInstaller is established. Install jobs typically output these objects
+// without a name or namespace, in which case they take those of the
+// PackageInstaller.
+func packageObjectName(obj metav1.Object, i v1alpha1.PackageInstaller) types.NamespacedName {
+	nn := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if nn.Name == "" {
+		nn.Name = i.GetName()
+	}
+	if nn.Namespace == "" {
+		nn.Namespace = i.GetNamespace()
+	}
+	return nn
+}
+
 // findPodNameForJob finds the pod name associated with the given job.  Note that this functions
 // assumes only a single pod will be associated with the job.
 func (jc *packageInstallJobCompleter) findPodNameForJob(ctx context.Context, job *batchv1.Job) (string, error) {
@@ -225,15 +843,120 @@ func (jc *packageInstallJobCompleter) readPodLogs(namespace, name string) (*byte
 	return b, nil
 }
 
+// establish the supplied object, tracing the outcome.
+func (jc *packageInstallJobCompleter) establish(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
+	ctx, span := jc.startSpan(ctx, spanEstablishObject,
+		Attribute{Key: attrGVK, Value: obj.GroupVersionKind().String()},
+		Attribute{Key: attrName, Value: obj.GetName()},
+	)
+	defer span.End()
+
+	o, err := jc.establishWithTimeout(ctx, obj, i, job)
+	span.SetAttributes(Attribute{Key: attrAction, Value: o.String()})
+	if err != nil {
+		span.RecordError(err)
+	}
+	return o, err
+}
+
+// establishWithTimeout establishes the supplied object, giving up if it takes
+// longer than the completer's per object timeout.
+func (jc *packageInstallJobCompleter) establishWithTimeout(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
+	if jc.timeout == 0 {
+		return jc.createJobOutputObject(ctx, obj, i, job)
+	}
+
+	octx, cancel := context.WithTimeout(ctx, jc.timeout)
+	defer cancel()
+	o, err := jc.createJobOutputObject(octx, obj, i, job)
+	if err != nil && octx.Err() == context.DeadlineExceeded {
+		return o, errors.Wrapf(err, "timed out after %s establishing %s %s", jc.timeout, obj.GroupVersionKind().String(), obj.GetName())
+	}
+	return o, err
+}
+
+// An establishOutcome describes what happened to an object output by an
+// install job when it was established.
+type establishOutcome int
+
+// Establish outcomes.
+const (
+	// outcomeSkipped objects already existed and were left untouched.
+	outcomeSkipped establishOutcome = iota
+
+	// outcomeCreated objects did not exist and were created.
+	outcomeCreated
+
+	// outcomeUpdated objects already existed, were managed by the package
+	// manager, and were updated if necessary.
+	outcomeUpdated
+
+	// outcomeAdopted objects already existed but were not managed by the
+	// package manager, which now manages them.
+	outcomeAdopted
+)
+
+// String returns the action taken for the outcome.
+func (o establishOutcome) String() string {
+	switch o {
+	case outcomeCreated:
+		return "create"
+	case outcomeUpdated:
+		return "update"
+	case outcomeAdopted:
+		return "adopt"
+	default:
+		return "skip"
+	}
+}
+
+// establishAll establishes the supplied objects output by the supplied job in
+// order, returning how many were established and how many of those were
+// adopted.
+func (jc *packageInstallJobCompleter) establishAll(ctx context.Context, objs []*unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (int, int, error) {
+	ctx, span := jc.startSpan(ctx, spanEstablish,
+		Attribute{Key: attrRevision, Value: i.GetNamespace() + "/" + i.GetName()},
+		Attribute{Key: attrJob, Value: job.GetNamespace() + "/" + job.GetName()},
+	)
+	defer span.End()
+
+	order := jc.order
+	if order == nil {
+		order = DefaultEstablishOrder
+	}
+	sortForEstablishment(objs, order)
+
+	// process and create the objects that we just decoded
+	established, adopted := 0, 0
+	for _, obj := range objs {
+		o, err := jc.establish(ctx, obj, i, job)
+		if err != nil {
+			span.RecordError(err)
+			return established, adopted, err
+		}
+		if o != outcomeSkipped {
+			established++
+		}
+		if o == outcomeAdopted {
+			adopted++
+		}
+		if jc.progress != nil {
+			jc.progress(i, established, len(objs))
+		}
+	}
+	return established, adopted, nil
+}
+
 // createJobOutputObject names, labels, and creates resources in the API
-// Expected resources are CRD, Package, & StackDefinition
+// Expected resources are CRD, Package, & StackDefinition. It returns the
+// outcome of establishing the object.
 // nolint:gocyclo
 func (jc *packageInstallJobCompleter) createJobOutputObject(ctx context.Context, obj *unstructured.Unstructured,
-	i v1alpha1.PackageInstaller, job *batchv1.Job) error {
+	i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
 
 	// if we decoded a non-nil unstructured object, try to create it now
 	if obj == nil {
-		return nil
+		return outcomeSkipped, nil
 	}
 
 	// Modify Package and StackDefinition resources based on PackageInstall
@@ -243,20 +966,21 @@ func (jc *packageInstallJobCompleter) createJobOutputObject(ctx context.Context,
 	if isPackage || isStackDefinition {
 		ns := i.GetNamespace()
 		name := i.GetName()
-		if obj.GetName() == "" {
-			obj.SetName(name)
-		}
-		if obj.GetNamespace() == "" {
-			obj.SetNamespace(ns)
-		}
+		nn := packageObjectName(obj, i)
+		obj.SetName(nn.Name)
+		obj.SetNamespace(nn.Namespace)
 
 		packageImg := i.GetPackage()
 
 		modifiers := []packageSpecModifier{
 			controllerImageInjector(packageImg),
-			controllerPullSetter(i.GetImagePullPolicy(), i.GetImagePullSecrets()),
+			controllerPullSetter(imagePullPolicy(i, jc.forceImagePullPolicy, jc.defaultImagePullPolicy), i.GetImagePullSecrets()),
 			controllerImageSourcer(i),
 			saAnnotationSetter(i.GetServiceAccountAnnotations()),
+			controllerSchedulingSetter(i.GetSchedulingOptions()),
+			controllerProbeSetter(i.GetProbeOptions()),
+			controllerReplicaSetter(i.GetReplicaOptions(), jc.log.WithValues("name", name, "namespace", ns).Info),
+			controllerRuntimeSetter(i.GetControllerRuntime()),
 		}
 
 		labels := packages.ParentLabels(i)
@@ -267,40 +991,124 @@ func (jc *packageInstallJobCompleter) createJobOutputObject(ctx context.Context,
 		if isStackDefinition {
 			modifiers = append(modifiers, controllerEnvSetter(ns, name))
 			if err := setupStackDefinitionController(obj, modifiers...); err != nil {
-				return err
+				return outcomeSkipped, err
 			}
 		} else if err := setupPackageController(obj, modifiers...); err != nil {
-			return err
+			return outcomeSkipped, err
 		}
 	}
 
-	jc.log.Debug(
-		"creating object from job output",
-		"job", job.Name,
-		"name", obj.GetName(),
-		"namespace", obj.GetNamespace(),
-		"apiVersion", obj.GetAPIVersion(),
-		"kind", obj.GetKind(),
-	)
+	jc.propagateAnnotationsTo(obj, i)
+
+	return jc.establisher().establishObject(ctx, obj, i, job)
+}
+
+// An objectEstablisher establishes an object output by a package install job,
+// once it has been prepared for the PackageInstaller that ran the job.
+type objectEstablisher interface {
+	establishObject(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error)
+}
+
+// establisher returns the objectEstablisher used to establish each object
+// output by a job; the completer itself, wrapped by any decorators.
+func (jc *packageInstallJobCompleter) establisher() objectEstablisher {
+	var e objectEstablisher = jc
+	for _, d := range jc.decorators {
+		e = d(e)
+	}
+	return e
+}
+
+// establishObject creates the supplied object, or updates it if it already
+// exists, returning the outcome of establishing it.
+func (jc *packageInstallJobCompleter) establishObject(ctx context.Context, obj *unstructured.Unstructured, i v1alpha1.PackageInstaller, job *batchv1.Job) (establishOutcome, error) {
+	log := jc.objectLogger(obj).WithValues("job", job.Name)
 
-	if err := jc.client.Create(ctx, obj); err != nil {
+	create, err := jc.legacyCRDConversion(obj)
+	if err != nil {
+		return outcomeSkipped, errors.Wrapf(err, "failed to convert CRD %s from job output %s", obj.GetName(), job.Name)
+	}
+	if create != obj {
+		jc.record.Event(i, event.Warning(reasonDeprecatedCRD, errors.Errorf("converted deprecated %s CRD %s from job output %s to %s", obj.GetAPIVersion(), obj.GetName(), job.Name, apiextensionsv1.SchemeGroupVersion.String())))
+	}
+
+	if err := jc.client.Create(ctx, create); err != nil {
 		if !kerrors.IsAlreadyExists(err) {
-			return errors.Wrapf(err, "failed to create object %s from job output %s", obj.GetName(), job.Name)
+			return outcomeSkipped, errors.Wrapf(err, "failed to create object %s from job output %s", obj.GetName(), job.Name)
 		}
 
 		if !isCRD(obj) {
-			return nil
+			o, err := jc.updateInstalledObject(ctx, i, obj)
+			return o, errors.Wrapf(err, "can not update existing object %s from job %s", obj.GetName(), job.Name)
 		}
 
-		if err := jc.replaceCRD(ctx, obj); err != nil {
-			return errors.Wrapf(err, "can not update existing CRD %s from job %s", obj.GetName(), job.Name)
+		o, err := jc.replaceCRD(ctx, i, obj)
+		if err != nil {
+			return outcomeSkipped, errors.Wrapf(err, "can not update existing CRD %s from job %s", obj.GetName(), job.Name)
 		}
+		return o, nil
 	}
 
-	return nil
+	log.Debug("created object from job output", "action", "create", "controlled", true)
+	return outcomeCreated, nil
 }
 
-func (jc *packageInstallJobCompleter) replaceCRD(ctx context.Context, obj *unstructured.Unstructured) error {
+// updateInstalledObject updates an existing Package or StackDefinition that
+// was previously established for the supplied PackageInstaller, so that it
+// reflects the supplied job output. Existing objects that were not established
+// for the PackageInstaller are left untouched.
+func (jc *packageInstallJobCompleter) updateInstalledObject(ctx context.Context, i v1alpha1.PackageInstaller, obj *unstructured.Unstructured) (establishOutcome, error) {
+	log := jc.objectLogger(obj)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := jc.client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, existing); err != nil {
+		return outcomeSkipped, errors.Wrap(err, "failed to fetch existing object")
+	}
+
+	for k, v := range packages.ParentLabels(i) {
+		if existing.GetLabels()[k] != v {
+			log.Debug("skipping object from job output that already exists", "action", "skip")
+			return outcomeSkipped, nil
+		}
+	}
+
+	existing.Object["spec"] = obj.Object["spec"]
+	meta.AddLabels(existing, obj.GetLabels())
+	meta.AddAnnotations(existing, obj.GetAnnotations())
+	if err := jc.client.Update(ctx, existing); err != nil {
+		return outcomeSkipped, errors.Wrap(err, "failed to update existing object")
+	}
+
+	log.Debug("updated object from job output that already exists", "action", "update")
+	return outcomeUpdated, nil
+}
+
+// propagateAnnotationsTo copies the annotations the completer propagates from
+// the supplied PackageInstaller to the supplied object. Annotations the
+// PackageInstaller does not have are not copied.
+func (jc *packageInstallJobCompleter) propagateAnnotationsTo(obj metav1.Object, i v1alpha1.PackageInstaller) {
+	a := map[string]string{}
+	for _, k := range jc.propagateAnnotations {
+		if v, ok := i.GetAnnotations()[k]; ok {
+			a[k] = v
+		}
+	}
+	if len(a) > 0 {
+		meta.AddAnnotations(obj, a)
+	}
+}
+
+// objectLogger returns a logger with fields that identify the supplied object.
+func (jc *packageInstallJobCompleter) objectLogger(obj *unstructured.Unstructured) logging.Logger {
+	return jc.log.WithValues(
+		"gvk", obj.GroupVersionKind().String(),
+		"name", obj.GetName(),
+		"namespace", obj.GetNamespace(),
+	)
+}
+
+func (jc *packageInstallJobCompleter) replaceCRD(ctx context.Context, i v1alpha1.PackageInstaller, obj *unstructured.Unstructured) (establishOutcome, error) {
 	existing := &apiextensions.CustomResourceDefinition{}
 	nsn := types.NamespacedName{
 		Namespace: obj.GetNamespace(),
@@ -308,28 +1116,253 @@ func (jc *packageInstallJobCompleter) replaceCRD(ctx context.Context, obj *unstr
 	}
 
 	if err := jc.client.Get(ctx, nsn, existing); err != nil {
-		return errors.Wrapf(err, "failed to fetch existing crd")
+		return outcomeSkipped, errors.Wrapf(err, "failed to fetch existing crd")
 	}
 
-	if meta.WasDeleted(existing) {
-		return errors.Errorf("failed due to pending deletion of existing crd")
+	// A CRD is controlled by the package manager if the package manager
+	// created it. If it was installed some other way we adopt it.
+	controlled := existing.GetLabels()[packages.LabelKubernetesManagedBy] == packages.LabelValuePackageManager
+	outcome := outcomeUpdated
+	if !controlled {
+		outcome = outcomeAdopted
+	}
+	log := jc.objectLogger(obj).WithValues("controlled", controlled)
+	log.Debug("fetched existing crd", "action", "get")
+
+	if !controlled {
+		switch jc.conflicts {
+		case ConflictAdopt:
+			// Adopt the CRD regardless of what controls it.
+			if by := controlledBy(existing); by != "" {
+				log.Debug("taking over existing crd controlled by something else", "controller", by, "action", "adopt")
+				jc.record.Event(i, event.Normal(reasonAdoptCRD, fmt.Sprintf("Adopted CRD %s, which was controlled by %s", obj.GetName(), by)))
+			}
+		case ConflictSkip:
+			log.Debug("skipping existing crd that is not managed by the package manager", "action", "skip")
+			return outcomeSkipped, nil
+		case ConflictFail:
+			return outcomeSkipped, permanent(errors.New(errCRDNotManaged))
+		default:
+			if by := controlledBy(existing); by != "" {
+				return outcomeSkipped, permanent(errors.Errorf(errFmtCRDControlled, by))
+			}
+		}
+	}
+
+	// The API server reports problems with a CRD via its status conditions.
+	// We surface these so that they're not hidden behind a retry loop.
+	if meta.WasDeleted(existing) || crdCondition(existing, apiextensions.Terminating) != nil {
+		i.SetConditions(v1alpha1.CRDTerminating(existing.GetName()))
+		return outcomeSkipped, errors.Errorf("failed due to pending deletion of existing crd")
+	}
+	if c := crdCondition(existing, apiextensions.NonStructuralSchema); c != nil {
+		// Replacing the schema may fix it, so we don't return early.
+		i.SetConditions(v1alpha1.CRDNonStructural(existing.GetName(), c.Message))
 	}
 
 	crd, err := convertToCRD(obj)
 	if err != nil {
-		return errors.Wrapf(err, "failed to convert unstructured crd from job log")
+		return outcomeSkipped, errors.Wrapf(err, "failed to convert unstructured crd from job log")
 	}
 
 	if !crdIsVersionsInclusive(existing, crd) {
-		return errors.Errorf("failed due to replacement crd lacking required versions")
+		return outcomeSkipped, permanent(errors.Errorf("failed due to replacement crd lacking required versions"))
+	}
+
+	// Objects are persisted at the storage version. Changing it without first
+	// migrating stored objects risks orphaning them.
+	from, to := crdStorageVersion(existing), crdStorageVersion(crd)
+	if from != "" && to != "" && from != to {
+		i.SetConditions(v1alpha1.StorageVersionChange(existing.GetName(), from, to))
+		return outcomeSkipped, permanent(errors.New(errStorageVersionChange))
 	}
 
 	// TODO(displague) reconsider preferring existing annotations over new
 	// annotations (example: new ui metadata)
 	meta.AddLabels(obj, existing.GetLabels())
 	meta.AddAnnotations(obj, existing.GetAnnotations())
+	jc.propagateAnnotationsTo(obj, i)
+
+	// Fields omitted from the patch are left as they are in the API server.
+	jc.removeImmutableFields(obj)
+
+	if jc.mergePrinterColumns {
+		if err := mergePrinterColumns(existing, obj); err != nil {
+			return outcomeSkipped, errors.Wrapf(err, "failed to merge printer columns of existing crd")
+		}
+	}
+
+	// Updating a CRD bumps its resource version and wakes every watcher of
+	// CRDs, so we don't update it unless something actually changed.
+	upToDate, err := jc.crdIsUpToDate(existing, obj)
+	if err != nil {
+		return outcomeSkipped, errors.Wrapf(err, "failed to compare existing crd")
+	}
+	if upToDate {
+		log.Debug("existing crd is up to date", "action", "none")
+		return outcome, nil
+	}
+
+	log.Debug("updating existing crd", "action", "update")
+	apply, err := jc.legacyCRDConversion(obj)
+	if err != nil {
+		return outcomeSkipped, errors.Wrapf(err, "failed to convert crd")
+	}
+	if err := resource.NewAPIPatchingApplicator(jc.client).Apply(ctx, apply); err != nil {
+		return outcomeSkipped, err
+	}
+	return outcome, nil
+}
+
+// controlledBy describes what controls the supplied object, if anything other
+// than the package manager does. An object is controlled by the owner that its
+// controller reference refers to, or by what its managed-by label names.
+func controlledBy(o metav1.Object) string {
+	if ref := metav1.GetControllerOf(o); ref != nil {
+		return fmt.Sprintf("%s %s", ref.Kind, ref.Name)
+	}
+	if by := o.GetLabels()[packages.LabelKubernetesManagedBy]; by != "" && by != packages.LabelValuePackageManager {
+		return by
+	}
+	return ""
+}
+
+// legacyCRDConversion returns the supplied object converted to an
+// apiextensions.k8s.io/v1 CRD if it is an apiextensions.k8s.io/v1beta1 CRD
+// and the completer is configured to convert legacy CRDs. Otherwise the
+// supplied object is returned.
+func (jc *packageInstallJobCompleter) legacyCRDConversion(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if !isCRD(obj) || jc.legacyCRDs != LegacyCRDConvert {
+		return obj, nil
+	}
+	return convertToV1CRD(obj)
+}
+
+// mergePrinterColumns adds the additional printer columns of the supplied
+// existing CRD that the supplied desired CRD lacks to the desired CRD. Columns
+// that apply to all versions are merged with those that apply to all versions,
+// and columns that apply to one version with those of the same version. The
+// desired CRD must be an apiextensions.k8s.io/v1beta1 CRD, as output by package
+// install jobs.
+func mergePrinterColumns(existing *apiextensions.CustomResourceDefinition, desired *unstructured.Unstructured) error {
+	cols, _, _ := unstructured.NestedSlice(desired.Object, "spec", "additionalPrinterColumns")
+	merged, err := appendMissingColumns(cols, existing.Spec.AdditionalPrinterColumns)
+	if err != nil {
+		return err
+	}
+	if len(merged) > 0 {
+		if err := unstructured.SetNestedSlice(desired.Object, merged, "spec", "additionalPrinterColumns"); err != nil {
+			return err
+		}
+	}
+
+	existingCols := map[string][]apiextensions.CustomResourceColumnDefinition{}
+	for _, v := range existing.Spec.Versions {
+		existingCols[v.Name] = v.AdditionalPrinterColumns
+	}
+
+	versions, _, _ := unstructured.NestedSlice(desired.Object, "spec", "versions")
+	for _, v := range versions {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		cols, _ := m["additionalPrinterColumns"].([]interface{})
+		merged, err := appendMissingColumns(cols, existingCols[name])
+		if err != nil {
+			return err
+		}
+		if len(merged) > 0 {
+			m["additionalPrinterColumns"] = merged
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	return unstructured.SetNestedSlice(desired.Object, versions, "spec", "versions")
+}
+
+// appendMissingColumns appends the supplied extra columns to the supplied
+// unstructured columns, unless a column of the same name is already present.
+func appendMissingColumns(cols []interface{}, extra []apiextensions.CustomResourceColumnDefinition) ([]interface{}, error) {
+	present := map[string]bool{}
+	for _, c := range cols {
+		if m, ok := c.(map[string]interface{}); ok {
+			name, _ := m["name"].(string)
+			present[name] = true
+		}
+	}
+
+	for idx := range extra {
+		if present[extra[idx].Name] {
+			continue
+		}
+		c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&extra[idx])
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+		present[extra[idx].Name] = true
+	}
+	return cols, nil
+}
+
+// removeImmutableFields removes any immutable fields for the supplied object's
+// kind from the supplied object.
+func (jc *packageInstallJobCompleter) removeImmutableFields(obj *unstructured.Unstructured) {
+	for _, path := range jc.immutableFields[obj.GroupVersionKind().GroupKind()] {
+		unstructured.RemoveNestedField(obj.Object, strings.Split(path, ".")...)
+	}
+}
+
+// crdIsUpToDate returns true if applying the desired CRD would not change the
+// existing CRD's labels, annotations, or spec. Status and server managed
+// metadata are ignored, as are any immutable fields.
+func (jc *packageInstallJobCompleter) crdIsUpToDate(existing *apiextensions.CustomResourceDefinition, desired *unstructured.Unstructured) (bool, error) {
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return false, err
+	}
+	u := &unstructured.Unstructured{Object: o}
+	u.SetGroupVersionKind(desired.GroupVersionKind())
+	jc.removeImmutableFields(u)
+
+	current, err := convertToCRD(u)
+	if err != nil {
+		return false, err
+	}
+	want, err := convertToCRD(desired)
+	if err != nil {
+		return false, err
+	}
+
+	return equality.Semantic.DeepEqual(current.GetLabels(), want.GetLabels()) &&
+		equality.Semantic.DeepEqual(current.GetAnnotations(), want.GetAnnotations()) &&
+		equality.Semantic.DeepEqual(current.Spec, want.Spec), nil
+}
+
+// crdCondition returns the supplied CRD's condition of the supplied type if
+// its status is True, or nil if it has no such condition.
+func crdCondition(crd *apiextensions.CustomResourceDefinition, ct apiextensions.CustomResourceDefinitionConditionType) *apiextensions.CustomResourceDefinitionCondition {
+	for i := range crd.Status.Conditions {
+		if c := crd.Status.Conditions[i]; c.Type == ct && c.Status == apiextensions.ConditionTrue {
+			return &crd.Status.Conditions[i]
+		}
+	}
+	return nil
+}
 
-	return resource.NewAPIPatchingApplicator(jc.client).Apply(ctx, obj)
+// crdStorageVersion returns the name of the supplied CRD's storage version,
+// or an empty string if none of its versions are marked as the storage
+// version.
+func crdStorageVersion(crd *apiextensions.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
 }
 
 // TODO(displague) this is copied from packages. centralize.
@@ -491,8 +1524,9 @@ func controllerImageSourcer(src imageWithSourcer) packageSpecModifier {
 func controllerImageInjector(packageImage string) packageSpecModifier {
 	return func(spec *v1alpha1.PackageSpec) error {
 		// If the package image is empty, we don't need to propagate an empty string
-		// down into more fields
-		if packageImage == "" {
+		// down into more fields. A package tarball is not an image, so its
+		// controller must specify its own.
+		if packageImage == "" || packages.IsTarballURL(packageImage) {
 			return nil
 		}
 
@@ -554,6 +1588,103 @@ func saAnnotationSetter(annotations map[string]string) packageSpecModifier {
 	}
 }
 
+// controllerSchedulingSetter applies the supplied scheduling options to the
+// pod template of the package's controller Deployment, if any.
+func controllerSchedulingSetter(o v1alpha1.SchedulingOptions) packageSpecModifier {
+	return func(spec *v1alpha1.PackageSpec) error {
+		d := spec.Controller.Deployment
+		if d == nil {
+			return nil
+		}
+
+		ps := &d.Spec.Template.Spec
+		if len(o.NodeSelector) > 0 {
+			if ps.NodeSelector == nil {
+				ps.NodeSelector = map[string]string{}
+			}
+			for k, v := range o.NodeSelector {
+				ps.NodeSelector[k] = v
+			}
+		}
+		ps.Tolerations = append(ps.Tolerations, o.Tolerations...)
+		if o.Affinity != nil {
+			ps.Affinity = o.Affinity.DeepCopy()
+		}
+
+		return nil
+	}
+}
+
+// controllerProbeSetter replaces the probes of the containers of the package's
+// controller Deployment, if any, with those supplied. Probes that are not
+// supplied are left as the package specified them.
+func controllerProbeSetter(o v1alpha1.ProbeOptions) packageSpecModifier {
+	return func(spec *v1alpha1.PackageSpec) error {
+		d := spec.Controller.Deployment
+		if d == nil {
+			return nil
+		}
+
+		c := d.Spec.Template.Spec.Containers
+		for i := range c {
+			if o.LivenessProbe != nil {
+				c[i].LivenessProbe = o.LivenessProbe.DeepCopy()
+			}
+			if o.ReadinessProbe != nil {
+				c[i].ReadinessProbe = o.ReadinessProbe.DeepCopy()
+			}
+		}
+
+		return nil
+	}
+}
+
+// controllerReplicaSetter sets the number of replicas of the package's
+// controller Deployment, if any. Controllers that run more than one replica are
+// told to elect a leader unless leader election is explicitly disabled, in
+// which case a warning is emitted.
+func controllerReplicaSetter(o v1alpha1.ReplicaOptions, warn func(msg string, keysAndValues ...interface{})) packageSpecModifier {
+	return func(spec *v1alpha1.PackageSpec) error {
+		d := spec.Controller.Deployment
+		if d == nil {
+			return nil
+		}
+
+		if o.Replicas != nil {
+			r := *o.Replicas
+			d.Spec.Replicas = &r
+		}
+
+		if d.Spec.Replicas == nil || *d.Spec.Replicas <= 1 {
+			return nil
+		}
+
+		if o.LeaderElection != nil && !*o.LeaderElection {
+			warn("package controller runs more than one replica without leader election", "replicas", *d.Spec.Replicas)
+			return nil
+		}
+
+		cs := d.Spec.Template.Spec.Containers
+		for i := range cs {
+			cs[i].Env = append(cs[i].Env, corev1.EnvVar{Name: packages.LeaderElectionEnv, Value: "true"})
+		}
+
+		return nil
+	}
+}
+
+// controllerRuntimeSetter removes the controller Deployment from the package
+// when its controller is run externally. The package's CRDs and RBAC are still
+// created, but the Package Manager does not run its controller.
+func controllerRuntimeSetter(r v1alpha1.ControllerRuntime) packageSpecModifier {
+	return func(spec *v1alpha1.PackageSpec) error {
+		if r == v1alpha1.ControllerRuntimeExternal {
+			spec.Controller.Deployment = nil
+		}
+		return nil
+	}
+}
+
 type packageSpecModifier func(spec *v1alpha1.PackageSpec) error
 
 // convertStackDefinitionToUnstructured takes a StackDefinition and converts it
@@ -598,6 +1729,36 @@ func convertToStackDefinition(o *unstructured.Unstructured) (*v1alpha1.StackDefi
 	return sd, nil
 }
 
+// convertToV1CRD converts the supplied apiextensions.k8s.io/v1beta1 CRD to an
+// apiextensions.k8s.io/v1 CRD. The CRD is defaulted as the API server would
+// default it, then converted via the internal apiextensions version using the
+// API server's own conversion functions, so that e.g. a top-level validation
+// schema becomes a per-version schema.
+func convertToV1CRD(o *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	in, err := convertToCRD(o)
+	if err != nil {
+		return nil, err
+	}
+	apiextensions.SetObjectDefaults_CustomResourceDefinition(in)
+
+	internal := &apiextensionsinternal.CustomResourceDefinition{}
+	if err := apiextensions.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(in, internal, nil); err != nil {
+		return nil, err
+	}
+	out := &apiextensionsv1.CustomResourceDefinition{}
+	if err := apiextensionsv1.Convert_apiextensions_CustomResourceDefinition_To_v1_CustomResourceDefinition(internal, out, nil); err != nil {
+		return nil, err
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(out)
+	if err != nil {
+		return nil, err
+	}
+	c := &unstructured.Unstructured{Object: u}
+	c.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	return c, nil
+}
+
 // convertToCRD takes a Kubernetes object and converts it into
 // *apiextensions.CustomResourceDefinition
 func convertToCRD(o *unstructured.Unstructured) (*apiextensions.CustomResourceDefinition, error) {
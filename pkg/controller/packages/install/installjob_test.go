@@ -29,20 +29,25 @@ import (
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	apps "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -78,6 +83,31 @@ spec:
   version: v1alpha1
 `
 
+	// managedCRDRaw is a CRD labelled as managed by the package manager, as
+	// unpack outputs it. The API server defaults the versions of a CRD that
+	// specifies only its version, but the fake client doesn't, so it
+	// specifies its versions in order to be replaceable.
+	managedCRDRaw = `---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: ` + crdName + `
+  labels:
+    ` + packages.LabelKubernetesManagedBy + `: ` + packages.LabelValuePackageManager + `
+spec:
+  group: samples.upbound.io
+  names:
+    kind: Mytype
+    listKind: MytypeList
+    plural: mytypes
+    singular: mytype
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+`
+
 	expectedStackDefinitionRaw = `---
 apiVersion: packages.crossplane.io/v1alpha1
 kind: StackDefinition
@@ -699,10 +729,39 @@ func TestHandleJobCompletion(t *testing.T) {
 				err: errors.WithStack(errors.Errorf("failed to parse output from job %s: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go value of type map[string]interface {}", resourceName)),
 			},
 		},
+		{
+			name: "HandleJobCompletionInvalidPackage",
+			jc: &packageInstallJobCompleter{
+				hostClient: &test.MockClient{
+					MockList: func(ctx context.Context, list runtime.Object, _ ...client.ListOption) error {
+						// LIST pods returns a pod for the job
+						*list.(*corev1.PodList) = corev1.PodList{
+							Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}},
+						}
+						return nil
+					},
+				},
+				podLogReader: &mockPodLogReader{
+					MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+						// The job outputs the same CRD twice, and no Package.
+						return ioutil.NopCloser(bytes.NewReader([]byte(crdRaw + "\n" + crdRaw))), nil
+					},
+				},
+				validatePackage: true,
+				log:             logging.NewNopLogger(),
+			},
+			ext: packageInstallResource(),
+			job: job(),
+			want: want{
+				ext: packageInstallResource(withConditions(v1alpha1.Invalid(errMissingPackage + "; " + fmt.Sprintf(errFmtDuplicateCRD, crdName)))),
+				err: errors.Wrapf(permanent(errors.New(errMissingPackage+"; "+fmt.Sprintf(errFmtDuplicateCRD, crdName))), "invalid output from job %s", resourceName),
+			},
+		},
 		{
 			name: "FailToCreate",
 			jc: &packageInstallJobCompleter{
 				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
 					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
 						return errBoom
 					},
@@ -726,7 +785,7 @@ func TestHandleJobCompletion(t *testing.T) {
 			ext: packageInstallResource(),
 			job: job(),
 			want: want{
-				ext: packageInstallResource(),
+				ext: packageInstallResource(withObjectCounts(0, 2), withConditions(v1alpha1.Establishing(0, 2))),
 				err: errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, resourceName),
 			},
 		},
@@ -734,6 +793,7 @@ func TestHandleJobCompletion(t *testing.T) {
 			name: "HandleJobCompletionSuccess",
 			jc: &packageInstallJobCompleter{
 				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
 					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
 						return nil
 					},
@@ -765,7 +825,83 @@ func TestHandleJobCompletion(t *testing.T) {
 			ext: packageInstallResource(),
 			job: job(),
 			want: want{
-				ext: packageInstallResource(),
+				ext: packageInstallResource(withObjectCounts(2, 2), withConditions(v1alpha1.Established(2))),
+				err: nil,
+			},
+		},
+		{
+			name: "HandleJobCompletionAdoptingCRD",
+			jc: &packageInstallJobCompleter{
+				client: func() client.Client {
+					// This CRD was not installed by the package manager.
+					crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"))
+					crd.SetResourceVersion("1")
+					fc := fake.NewFakeClient(&crd)
+					return &test.MockClient{
+						MockGet:    fc.Get,
+						MockCreate: fc.Create,
+						MockPatch:  fc.Patch,
+						MockList:   test.NewMockListFn(nil),
+					}
+				}(),
+				hostClient: &test.MockClient{
+					MockList: func(ctx context.Context, list runtime.Object, _ ...client.ListOption) error {
+						// LIST pods returns a pod for the job
+						*list.(*corev1.PodList) = corev1.PodList{
+							Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}},
+						}
+						return nil
+					},
+				},
+				podLogReader: &mockPodLogReader{
+					MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+						return ioutil.NopCloser(bytes.NewReader([]byte(podLogOutput))), nil
+					},
+				},
+				log: logging.NewNopLogger(),
+			},
+			ext: packageInstallResource(),
+			job: job(),
+			want: want{
+				ext: packageInstallResource(withObjectCounts(2, 2), withAdoptedCount(1), withConditions(v1alpha1.Established(2))),
+				err: nil,
+			},
+		},
+		{
+			name: "HandleJobCompletionObjectCountMismatch",
+			jc: &packageInstallJobCompleter{
+				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
+					// The Package already exists but was not established for
+					// this install, so it is skipped; the CRD is created.
+					MockGet: test.NewMockGetFn(nil),
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+						if u, ok := obj.(*unstructured.Unstructured); ok && isPackageObject(u) {
+							return kerrors.NewAlreadyExists(schema.GroupResource{}, u.GetName())
+						}
+						return nil
+					},
+				},
+				hostClient: &test.MockClient{
+					MockList: func(ctx context.Context, list runtime.Object, _ ...client.ListOption) error {
+						// LIST pods returns a pod for the job
+						*list.(*corev1.PodList) = corev1.PodList{
+							Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}},
+						}
+						return nil
+					},
+				},
+				podLogReader: &mockPodLogReader{
+					MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+						return ioutil.NopCloser(bytes.NewReader([]byte(podLogOutput))), nil
+					},
+				},
+				log: logging.NewNopLogger(),
+			},
+			ext: packageInstallResource(),
+			job: job(),
+			want: want{
+				ext: packageInstallResource(withObjectCounts(1, 2), withConditions(v1alpha1.ObjectCountMismatch(2, 1))),
 				err: nil,
 			},
 		},
@@ -773,6 +909,7 @@ func TestHandleJobCompletion(t *testing.T) {
 			name: "HandleJobCompletionWithSource",
 			jc: &packageInstallJobCompleter{
 				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
 					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
 						if u, ok := obj.(*unstructured.Unstructured); ok {
 							if isPackageObject(u) {
@@ -824,7 +961,7 @@ func TestHandleJobCompletion(t *testing.T) {
 			ext: packageInstallResource(withSource(packageInstallSource)),
 			job: job(withJobSource(packageInstallSource)),
 			want: want{
-				ext: packageInstallResource(withSource(packageInstallSource)),
+				ext: packageInstallResource(withSource(packageInstallSource), withObjectCounts(2, 2), withConditions(v1alpha1.Established(2))),
 				err: nil,
 			},
 		},
@@ -832,6 +969,7 @@ func TestHandleJobCompletion(t *testing.T) {
 			name: "HandleJobCompletionWithPullPolicy",
 			jc: &packageInstallJobCompleter{
 				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
 					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
 						if u, ok := obj.(*unstructured.Unstructured); ok {
 							if isPackageObject(u) {
@@ -891,6 +1029,7 @@ func TestHandleJobCompletion(t *testing.T) {
 					withSource(packageInstallSource),
 					withImagePullPolicy(corev1.PullAlways),
 					withImagePullSecrets([]corev1.LocalObjectReference{{Name: "foo"}}),
+					withObjectCounts(2, 2), withConditions(v1alpha1.Established(2)),
 				),
 				err: nil,
 			},
@@ -914,140 +1053,1352 @@ func TestHandleJobCompletion(t *testing.T) {
 	}
 }
 
-func TestCreate(t *testing.T) {
-	type want struct {
-		result reconcile.Result
-		err    error
-		ext    *v1alpha1.PackageInstall
-		job    *batchv1.Job
+func TestAPIEstablisher(t *testing.T) {
+	// The objects are established in a different API server from the one
+	// the PackageInstall lives in. Nothing was established previously, so
+	// there is nothing to prune.
+	fc := fake.NewFakeClient()
+	e := NewAPIEstablisherForClient(&test.MockClient{MockCreate: fc.Create, MockGet: fc.Get, MockList: test.NewMockListFn(nil)})
+
+	ext := packageInstallResource()
+	objs := []*unstructured.Unstructured{unstructuredObj(crdRaw), unstructuredObj(packageRaw("crossplane/sample-package:latest"))}
+	if err := e.Establish(context.Background(), ext, job(), objs); err != nil {
+		t.Fatalf("Establish(...): %s", err)
 	}
 
-	noJobs := func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
-		return kerrors.NewNotFound(schema.GroupResource{Group: "batch", Resource: "Job"}, key.String())
+	if err := fc.Get(context.Background(), types.NamespacedName{Name: crdName}, &apiextensions.CustomResourceDefinition{}); err != nil {
+		t.Errorf("Establish(...): wanted CRD %s to be established: %s", crdName, err)
+	}
+	if err := fc.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: resourceName}, &v1alpha1.Package{}); err != nil {
+		t.Errorf("Establish(...): wanted Package %s to be established: %s", resourceName, err)
 	}
 
-	tests := []struct {
-		name    string
-		handler *packageInstallHandler
+	want := packageInstallResource(withObjectCounts(2, 2), withConditions(v1alpha1.Established(2)))
+	if diff := cmp.Diff(want, ext, test.EquateConditions()); diff != "" {
+		t.Errorf("Establish(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestHandleJobCompletionProgress(t *testing.T) {
+	type progress struct{ done, total int }
+
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err      error
+		progress []progress
+		ext      *v1alpha1.PackageInstall
+	}
+
+	cases := map[string]struct {
+		reason  string
+		creates int
 		want    want
 	}{
-		{
-			name: "CreateInstallJob",
-			handler: &packageInstallHandler{
-				kube: &test.MockClient{
-					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
-						return nil
-					},
-					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
-						return nil
-					},
-				},
-				hostKube: &test.MockClient{
-					MockGet:    noJobs,
-					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return nil },
-				},
-				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
-				ext:          packageInstallResource(),
-				log:          logging.NewNopLogger(),
-			},
+		"Established": {
+			reason:  "Progress should be reported as each object is established, and the package considered established once all are.",
+			creates: 2,
 			want: want{
-				result: requeueOnSuccess,
-				err:    nil,
-				ext: packageInstallResource(
-					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
-					withInstallJob(&corev1.ObjectReference{
-						Name:       resourceName,
-						Namespace:  namespace,
-						Kind:       "Job",
-						APIVersion: batchv1.SchemeGroupVersion.String(),
-					}),
-				),
+				progress: []progress{{1, 2}, {2, 2}},
+				ext:      packageInstallResource(withObjectCounts(2, 2), withConditions(v1alpha1.Established(2))),
 			},
 		},
-		{
-			name: "CreateInstallJobForcedPullPolicy",
-			handler: &packageInstallHandler{
-				forceImagePullPolicy: string(corev1.PullAlways),
-				kube: &test.MockClient{
-					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
-						return nil
-					},
-					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
-						return nil
-					},
-				},
-				hostKube:     fake.NewFakeClient(),
-				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
-				ext:          packageInstallResource(withImagePullPolicy(corev1.PullNever)),
-				log:          logging.NewNopLogger(),
-			},
+		"PartiallyEstablished": {
+			reason:  "The objects established before a failure should be counted, and the package should not yet be considered established.",
+			creates: 1,
 			want: want{
-				result: requeueOnSuccess,
-				err:    nil,
-				ext: packageInstallResource(
-					withImagePullPolicy(corev1.PullNever),
-					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
-					withInstallJob(&corev1.ObjectReference{
-						Name:       resourceName,
-						Namespace:  namespace,
-						Kind:       "Job",
-						APIVersion: batchv1.SchemeGroupVersion.String(),
-					}),
-				),
-				job: job(
-					withJobExpectations(),
-					withJobPullPolicy(corev1.PullAlways),
-				),
+				err:      errors.Wrapf(errBoom, "failed to create object %s from job output %s", resourceName, resourceName),
+				progress: []progress{{1, 2}},
+				ext:      packageInstallResource(withObjectCounts(1, 2), withConditions(v1alpha1.Establishing(1, 2))),
 			},
 		},
-		{
-			name: "CreateInstallJobHosted",
-			handler: &packageInstallHandler{
-				kube: &test.MockClient{
+	}
 
-					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []progress{}
+			created := 0
+
+			jc := &packageInstallJobCompleter{
+				client: &test.MockClient{
+					MockList: test.NewMockListFn(nil),
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+						// Only the first n objects can be created.
+						if created == tc.creates {
+							return errBoom
+						}
+						created++
 						return nil
 					},
-					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+				},
+				hostClient: &test.MockClient{
+					MockList: func(ctx context.Context, list runtime.Object, _ ...client.ListOption) error {
+						// LIST pods returns a pod for the job
+						*list.(*corev1.PodList) = corev1.PodList{
+							Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}},
+						}
 						return nil
 					},
 				},
-				hostKube: &test.MockClient{
-					MockGet:    noJobs,
-					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return nil },
-					MockList:   func(ctx context.Context, obj runtime.Object, _ ...client.ListOption) error { return nil },
+				podLogReader: &mockPodLogReader{
+					MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+						return ioutil.NopCloser(bytes.NewReader([]byte(podLogOutput))), nil
+					},
 				},
-				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
-				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
-				ext:             packageInstallResource(),
-				log:             logging.NewNopLogger(),
-			},
+				log: logging.NewNopLogger(),
+			}
+			WithProgress(func(_ v1alpha1.PackageInstaller, done, total int) { got = append(got, progress{done, total}) })(jc)
+
+			ext := packageInstallResource()
+			err := jc.handleJobCompletion(context.Background(), ext, job())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nhandleJobCompletion(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, got, cmp.AllowUnexported(progress{})); diff != "" {
+				t.Errorf("\n%s\nhandleJobCompletion(): -want progress, +got progress:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ext, ext, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nhandleJobCompletion(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConflictStrategy(t *testing.T) {
+	// existing returns a client whose CRD was not installed by the package
+	// manager, and is controlled by the supplied owner, if any.
+	existing := func(owner *metav1.OwnerReference) client.Client {
+		crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"))
+		crd.SetResourceVersion("1")
+		if owner != nil {
+			crd.SetOwnerReferences([]metav1.OwnerReference{*owner})
+		}
+		fc := fake.NewFakeClient(&crd)
+		return &test.MockClient{MockGet: fc.Get, MockPatch: fc.Patch}
+	}
+	controller := true
+	foreign := &metav1.OwnerReference{APIVersion: "example.org/v1", Kind: "Operator", Name: "cool", UID: "cool-uid", Controller: &controller}
+
+	type want struct {
+		outcome establishOutcome
+		err     error
+		events  []event.Event
+	}
+
+	cases := map[string]struct {
+		reason   string
+		client   client.Client
+		strategy ConflictStrategy
+		want     want
+	}{
+		"DefaultUncontrolled": {
+			reason: "By default an existing CRD that is not controlled by anything should be adopted",
+			client: existing(nil),
+			want:   want{outcome: outcomeAdopted},
+		},
+		"DefaultForeignOwned": {
+			reason: "By default an existing CRD that is controlled by something else should not be established",
+			client: existing(foreign),
+			want:   want{outcome: outcomeSkipped, err: permanent(errors.Errorf(errFmtCRDControlled, "Operator cool"))},
+		},
+		"AdoptForeignOwned": {
+			reason:   "The Adopt strategy should adopt an existing CRD that is controlled by something else",
+			client:   existing(foreign),
+			strategy: ConflictAdopt,
 			want: want{
-				result: requeueOnSuccess,
-				err:    nil,
-				ext: packageInstallResource(
-					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
-					withInstallJob(&corev1.ObjectReference{
-						Name:       fmt.Sprintf("%s.%s", namespace, resourceName),
-						Namespace:  hostControllerNamespace,
-						Kind:       "Job",
-						APIVersion: batchv1.SchemeGroupVersion.String(),
-					}),
-				),
+				outcome: outcomeAdopted,
+				events:  []event.Event{event.Normal(reasonAdoptCRD, fmt.Sprintf("Adopted CRD %s, which was controlled by %s", crdName, "Operator cool"))},
 			},
 		},
-		{
-			name: "CreateInstallJobHostedWithPullSecrets",
-			handler: &packageInstallHandler{
-				kube: fake.NewFakeClient(
-					secret("secret", namespace),
-					packageInstallResource(withImagePullSecrets([]corev1.LocalObjectReference{{Name: "secret"}})),
-				),
-				hostKube:        fake.NewFakeClient(),
-				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
+		"SkipForeignOwned": {
+			reason:   "The Skip strategy should leave an existing CRD that is controlled by something else untouched",
+			client:   existing(foreign),
+			strategy: ConflictSkip,
+			want:     want{outcome: outcomeSkipped},
+		},
+		"FailForeignOwned": {
+			reason:   "The Fail strategy should refuse to establish an existing CRD that is controlled by something else",
+			client:   existing(foreign),
+			strategy: ConflictFail,
+			want:     want{outcome: outcomeSkipped, err: permanent(errors.New(errCRDNotManaged))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			record := &eventRecorder{}
+			jc := &packageInstallJobCompleter{client: tc.client, log: logging.NewNopLogger(), record: record}
+			WithConflictStrategy(tc.strategy)(jc)
+
+			got, err := jc.replaceCRD(context.Background(), packageInstallResource(), unstructuredObj(crdRaw))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nreplaceCRD(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.outcome, got); diff != "" {
+				t.Errorf("\n%s\nreplaceCRD(...): -want outcome, +got outcome:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, record.events); diff != "" {
+				t.Errorf("\n%s\nreplaceCRD(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHandleJobCompletionConflictSkip(t *testing.T) {
+	// The existing CRD is controlled by something other than the package
+	// manager.
+	controller := true
+	existing := crd(withCRDGroupKind("samples.upbound.io", "Mytype"))
+	existing.SetResourceVersion("1")
+	existing.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "example.org/v1", Kind: "Operator", Name: "cool", UID: "cool-uid", Controller: &controller}})
+	fc := fake.NewFakeClient(&existing)
+
+	jc := &packageInstallJobCompleter{
+		client: &test.MockClient{MockCreate: fc.Create, MockGet: fc.Get, MockPatch: fc.Patch, MockList: test.NewMockListFn(nil)},
+		hostClient: &test.MockClient{
+			MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+				*list.(*corev1.PodList) = corev1.PodList{Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}}}
+				return nil
+			},
+		},
+		podLogReader: &mockPodLogReader{
+			MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+				// The job outputs only the conflicting CRD.
+				return ioutil.NopCloser(bytes.NewReader([]byte(crdRaw))), nil
+			},
+		},
+		log: logging.NewNopLogger(),
+	}
+	WithConflictStrategy(ConflictSkip)(jc)
+	WithCachingEstablisher(NewCachingEstablisher())(jc)
+
+	// The conflicting CRD should still be skipped when the job's output is
+	// established again, and the package should not be considered
+	// established either time.
+	i := packageInstallResource()
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := jc.handleJobCompletion(context.Background(), i, job()); err != nil {
+			t.Fatalf("handleJobCompletion(...): attempt %d: %s", attempt, err)
+		}
+		want := packageInstallResource(withObjectCounts(0, 1), withConditions(v1alpha1.ObjectCountMismatch(1, 0)))
+		if diff := cmp.Diff(want, i, test.EquateConditions()); diff != "" {
+			t.Errorf("handleJobCompletion(...): attempt %d: -want, +got:\n%s", attempt, diff)
+		}
+
+		got := &apiextensions.CustomResourceDefinition{}
+		if err := fc.Get(context.Background(), types.NamespacedName{Name: crdName}, got); err != nil {
+			t.Fatalf("Get(...): %s", err)
+		}
+		if diff := cmp.Diff(existing.GetOwnerReferences(), got.GetOwnerReferences()); diff != "" {
+			t.Errorf("handleJobCompletion(...): attempt %d: the skipped CRD should be untouched: -want owner references, +got owner references:\n%s", attempt, diff)
+		}
+	}
+}
+
+func TestHandleJobCompletionExistingObjects(t *testing.T) {
+	output := managedCRDRaw + packageRaw("crossplane/sample-package:latest")
+
+	fc := fake.NewFakeClient()
+	jc := &packageInstallJobCompleter{
+		client: &test.MockClient{MockCreate: fc.Create, MockGet: fc.Get, MockPatch: fc.Patch, MockUpdate: fc.Update, MockList: test.NewMockListFn(nil)},
+		hostClient: &test.MockClient{
+			MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+				*list.(*corev1.PodList) = corev1.PodList{Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}}}
+				return nil
+			},
+		},
+		podLogReader: &mockPodLogReader{
+			MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader([]byte(output))), nil
+			},
+		},
+		log:    logging.NewNopLogger(),
+		record: event.NewNopRecorder(),
+	}
+
+	// The objects established the first time already exist the second time.
+	// They should be updated to reflect the job output and the install's
+	// current configuration, and considered established both times.
+	i := packageInstallResource()
+	for attempt := 1; attempt <= 2; attempt++ {
+		if attempt == 2 {
+			i.Spec.NodeSelector = map[string]string{"pool": "providers"}
+		}
+		if err := jc.handleJobCompletion(context.Background(), i, job()); err != nil {
+			t.Fatalf("handleJobCompletion(...): attempt %d: %s", attempt, err)
+		}
+
+		want := packageInstallResource(withObjectCounts(2, 2), withConditions(v1alpha1.Established(2)))
+		want.Spec.NodeSelector = i.Spec.NodeSelector
+		if diff := cmp.Diff(want, i, test.EquateConditions()); diff != "" {
+			t.Errorf("handleJobCompletion(...): attempt %d: -want, +got:\n%s", attempt, diff)
+		}
+	}
+
+	got := &v1alpha1.Package{}
+	if err := fc.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: resourceName}, got); err != nil {
+		t.Fatalf("Get(...): %s", err)
+	}
+	if diff := cmp.Diff(i.Spec.NodeSelector, got.Spec.Controller.Deployment.Spec.Template.Spec.NodeSelector); diff != "" {
+		t.Errorf("handleJobCompletion(...): the existing Package should be updated: -want node selector, +got node selector:\n%s", diff)
+	}
+}
+
+func TestHandleJobCompletionFilteredPackageKept(t *testing.T) {
+	output := managedCRDRaw + packageRaw("crossplane/sample-package:latest")
+
+	// The Package was established before the filter was applied.
+	existing := &v1alpha1.Package{ObjectMeta: metav1.ObjectMeta{
+		Namespace: namespace,
+		Name:      resourceName,
+		Labels:    packages.ParentLabels(packageInstallResource()),
+	}}
+	fc := fake.NewFakeClient(existing)
+	jc := &packageInstallJobCompleter{
+		client: fc,
+		hostClient: &test.MockClient{
+			MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+				*list.(*corev1.PodList) = corev1.PodList{Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}}}
+				return nil
+			},
+		},
+		podLogReader: &mockPodLogReader{
+			MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader([]byte(output))), nil
+			},
+		},
+		log:    logging.NewNopLogger(),
+		record: event.NewNopRecorder(),
+	}
+	WithEstablishFilter(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"})(jc)
+
+	if err := jc.handleJobCompletion(context.Background(), packageInstallResource(), job()); err != nil {
+		t.Fatalf("handleJobCompletion(...): %s", err)
+	}
+
+	// The Package output by the job has no name or namespace, but it is
+	// still the existing Package, so it should not be pruned.
+	if err := fc.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: resourceName}, &v1alpha1.Package{}); err != nil {
+		t.Errorf("handleJobCompletion(...): wanted the Package the filter left out to be kept: %s", err)
+	}
+}
+
+func TestAnnotationPropagation(t *testing.T) {
+	const (
+		commit   = "example.org/commit"
+		other    = "example.org/other"
+		unlisted = "example.org/unlisted"
+	)
+
+	cases := map[string]struct {
+		reason   string
+		existing map[string]string
+		want     map[string]string
+	}{
+		"Created": {
+			reason: "Only the listed annotations should be propagated to a created object",
+			want:   map[string]string{commit: "new"},
+		},
+		"Updated": {
+			reason:   "Listed annotations should be updated on an existing object, without touching unlisted annotations",
+			existing: map[string]string{commit: "old", other: "untouched"},
+			want:     map[string]string{commit: "new", other: "untouched"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fc := fake.NewFakeClient()
+			if tc.existing != nil {
+				existing := crd(withCRDGroupKind("samples.upbound.io", "Mytype"))
+				existing.SetResourceVersion("1")
+				existing.SetAnnotations(tc.existing)
+				fc = fake.NewFakeClient(&existing)
+			}
+
+			jc := &packageInstallJobCompleter{client: fc, log: logging.NewNopLogger()}
+			WithAnnotationPropagation(commit)(jc)
+
+			i := packageInstallResource(withAnnotations(map[string]string{commit: "new", unlisted: "ignored"}))
+			if _, err := jc.createJobOutputObject(context.Background(), unstructuredObj(crdRaw), i, job()); err != nil {
+				t.Fatalf("\n%s\ncreateJobOutputObject(...): %s", tc.reason, err)
+			}
+
+			got := &apiextensions.CustomResourceDefinition{}
+			if err := fc.Get(context.Background(), types.NamespacedName{Name: crdName}, got); err != nil {
+				t.Fatalf("\n%s\nGet(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got.GetAnnotations()); diff != "" {
+				t.Errorf("\n%s\ncreateJobOutputObject(...): -want annotations, +got annotations:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergePrinterColumns(t *testing.T) {
+	existing := func(top []apiextensions.CustomResourceColumnDefinition, v1alpha1Cols []apiextensions.CustomResourceColumnDefinition) *apiextensions.CustomResourceDefinition {
+		c := &apiextensions.CustomResourceDefinition{}
+		c.Spec.AdditionalPrinterColumns = top
+		c.Spec.Versions = []apiextensions.CustomResourceDefinitionVersion{{Name: "v1alpha1", AdditionalPrinterColumns: v1alpha1Cols}}
+		return c
+	}
+	desired := func(apiVersion string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       "CustomResourceDefinition",
+			"spec":       spec,
+		}}
+	}
+	userCol := apiextensions.CustomResourceColumnDefinition{Name: "DASHBOARD", Type: "string", JSONPath: ".status.dashboard", Priority: 1}
+	overriddenCol := apiextensions.CustomResourceColumnDefinition{Name: "READY", Type: "string", JSONPath: ".status.old"}
+
+	cases := map[string]struct {
+		reason   string
+		existing *apiextensions.CustomResourceDefinition
+		desired  *unstructured.Unstructured
+		want     map[string]interface{}
+	}{
+		"AllVersions": {
+			reason:   "Columns added to an existing CRD for all versions should be kept, while those the package outputs take precedence",
+			existing: existing([]apiextensions.CustomResourceColumnDefinition{overriddenCol, userCol}, nil),
+			desired: desired("apiextensions.k8s.io/v1beta1", map[string]interface{}{
+				"additionalPrinterColumns": []interface{}{
+					map[string]interface{}{"name": "READY", "type": "string", "JSONPath": ".status.ready"},
+				},
+			}),
+			want: map[string]interface{}{
+				"additionalPrinterColumns": []interface{}{
+					map[string]interface{}{"name": "READY", "type": "string", "JSONPath": ".status.ready"},
+					map[string]interface{}{"name": "DASHBOARD", "type": "string", "JSONPath": ".status.dashboard", "priority": int64(1)},
+				},
+			},
+		},
+		"PerVersion": {
+			reason:   "Columns added to a version of an existing CRD should be kept for that version",
+			existing: existing(nil, []apiextensions.CustomResourceColumnDefinition{userCol}),
+			desired: desired("apiextensions.k8s.io/v1beta1", map[string]interface{}{
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1alpha1"},
+					map[string]interface{}{"name": "v1alpha2"},
+				},
+			}),
+			want: map[string]interface{}{
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1alpha1", "additionalPrinterColumns": []interface{}{
+						map[string]interface{}{"name": "DASHBOARD", "type": "string", "JSONPath": ".status.dashboard", "priority": int64(1)},
+					}},
+					map[string]interface{}{"name": "v1alpha2"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := mergePrinterColumns(tc.existing, tc.desired); err != nil {
+				t.Fatalf("\n%s\nmergePrinterColumns(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, tc.desired.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nmergePrinterColumns(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEstablishPerObjectTimeout(t *testing.T) {
+	jc := &packageInstallJobCompleter{
+		client: &test.MockClient{
+			// Create hangs until its context is done.
+			MockCreate: func(ctx context.Context, _ runtime.Object, _ ...client.CreateOption) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		log: logging.NewNopLogger(),
+	}
+	WithPerObjectTimeout(time.Millisecond)(jc)
+
+	obj := unstructuredObj(crdRaw)
+	_, err := jc.establish(context.Background(), obj, packageInstallResource(), job())
+
+	want := errors.Wrapf(
+		errors.Wrapf(context.DeadlineExceeded, "failed to create object %s from job output %s", crdName, resourceName),
+		"timed out after %s establishing %s %s", time.Millisecond, obj.GroupVersionKind().String(), crdName)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("establish(): -want error, +got error:\n%s", diff)
+	}
+}
+
+// An eventRecorder records the events it is asked to record.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestLegacyCRDConversion(t *testing.T) {
+	v1CRD := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+	type want struct {
+		err      error
+		versions []interface{}
+		events   []event.Event
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []JobCompleterOption
+		want   want
+	}{
+		"Convert": {
+			reason: "A v1beta1 CRD should be established as a v1 CRD, and a warning recorded, when legacy CRDs are converted.",
+			opts:   []JobCompleterOption{WithLegacyCRDPolicy(LegacyCRDConvert)},
+			want: want{
+				versions: []interface{}{map[string]interface{}{"name": "v1alpha1", "served": true, "storage": true}},
+				events: []event.Event{event.Warning(reasonDeprecatedCRD, errors.Errorf("converted deprecated %s CRD %s from job output %s to %s",
+					"apiextensions.k8s.io/v1beta1", crdName, resourceName, "apiextensions.k8s.io/v1"))},
+			},
+		},
+		"Establish": {
+			reason: "A v1beta1 CRD should be established as it is, without a warning, when legacy CRDs are established.",
+			opts:   []JobCompleterOption{WithLegacyCRDPolicy(LegacyCRDEstablish)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fc := fake.NewFakeClient()
+			record := &eventRecorder{}
+			jc := &packageInstallJobCompleter{client: fc, log: logging.NewNopLogger(), record: record}
+			for _, o := range tc.opts {
+				o(jc)
+			}
+
+			_, err := jc.createJobOutputObject(context.Background(), unstructuredObj(crdRaw), packageInstallResource(), job())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncreateJobOutputObject(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, record.events); diff != "" {
+				t.Errorf("\n%s\ncreateJobOutputObject(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if tc.want.versions == nil {
+				return
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(v1CRD)
+			if err := fc.Get(context.Background(), types.NamespacedName{Name: crdName}, got); err != nil {
+				t.Fatalf("Get(...): %s", err)
+			}
+			versions, _, _ := unstructured.NestedSlice(got.Object, "spec", "versions")
+			if diff := cmp.Diff(tc.want.versions, versions); diff != "" {
+				t.Errorf("\n%s\nspec.versions: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"Transient": {
+			reason: "Errors are retryable by default.",
+			err:    errors.Wrap(errBoom, "failed to establish"),
+			want:   true,
+		},
+		"Permanent": {
+			reason: "Errors marked as permanent, however deeply wrapped, are not retryable.",
+			err:    errors.Wrap(errors.Wrap(permanent(errBoom), "failed to establish"), "failed to complete job"),
+			want:   false,
+		},
+		"Invalid": {
+			reason: "Errors returned by the API server because an object is invalid are not retryable.",
+			err:    errors.Wrap(kerrors.NewInvalid(schema.GroupKind{Kind: "Mytype"}, "cool", nil), "failed to establish"),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := retryable(tc.err); got != tc.want {
+				t.Errorf("\n%s\nretryable(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateCRDGroups(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		objs   []*unstructured.Unstructured
+		want   error
+	}{
+		"OwnedGroup": {
+			reason: "A CRD in a group owned by the package should be allowed.",
+			objs:   []*unstructured.Unstructured{unstructuredObj(crdRaw), unstructuredObj(packageRaw(packageEnvelopeImage))},
+		},
+		"UnownedGroup": {
+			reason: "A CRD in a group that is not owned by the package should be rejected.",
+			objs: []*unstructured.Unstructured{
+				unstructuredObj(crdRaw, unstructuredAsCRD(withCRDGroupKind("example.org", "Sneaky"))),
+				unstructuredObj(packageRaw(packageEnvelopeImage)),
+			},
+			want: permanent(errors.Errorf("%s: crd %s is in group %q", errCRDGroupNotAllowed, "sneakys.example.org", "example.org")),
+		},
+		"NoPackage": {
+			reason: "A CRD should be rejected if no package declares what it owns.",
+			objs:   []*unstructured.Unstructured{unstructuredObj(crdRaw)},
+			want:   permanent(errors.Errorf("%s: crd %s is in group %q", errCRDGroupNotAllowed, crdName, "samples.upbound.io")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateCRDGroups(tc.objs)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateCRDGroups(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSortForEstablishment(t *testing.T) {
+	obj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+	names := func(objs []*unstructured.Unstructured) []string {
+		n := make([]string, len(objs))
+		for i := range objs {
+			n[i] = objs[i].GetName()
+		}
+		return n
+	}
+
+	tests := []struct {
+		name  string
+		objs  []*unstructured.Unstructured
+		order []schema.GroupKind
+		want  []string
+	}{
+		{
+			name: "DefaultOrder",
+			objs: []*unstructured.Unstructured{
+				obj("packages.crossplane.io/v1alpha1", "Package", "package"),
+				obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd-a"),
+				obj("packages.crossplane.io/v1alpha1", "StackDefinition", "stackdefinition"),
+				obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd-b"),
+			},
+			order: DefaultEstablishOrder,
+			want:  []string{"crd-a", "crd-b", "package", "stackdefinition"},
+		},
+		{
+			name: "CustomOrder",
+			objs: []*unstructured.Unstructured{
+				obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd"),
+				obj("v1", "ConfigMap", "configmap"),
+				obj("packages.crossplane.io/v1alpha1", "Package", "package"),
+			},
+			order: []schema.GroupKind{{Group: "packages.crossplane.io", Kind: "Package"}},
+			want:  []string{"package", "crd", "configmap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortForEstablishment(tt.objs, tt.order)
+			if diff := cmp.Diff(tt.want, names(tt.objs)); diff != "" {
+				t.Errorf("sortForEstablishment(): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterForEstablishment(t *testing.T) {
+	obj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+	names := func(objs []*unstructured.Unstructured) []string {
+		n := make([]string, len(objs))
+		for i := range objs {
+			n[i] = objs[i].GetName()
+		}
+		return n
+	}
+	objs := []*unstructured.Unstructured{
+		obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd-a"),
+		obj("packages.crossplane.io/v1alpha1", "Package", "package"),
+		obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd-b"),
+		obj("packages.crossplane.io/v1alpha1", "StackDefinition", "stackdefinition"),
+	}
+
+	tests := []struct {
+		name   string
+		filter []schema.GroupKind
+		want   []string
+	}{
+		{
+			name: "NoFilter",
+			want: []string{"crd-a", "package", "crd-b", "stackdefinition"},
+		},
+		{
+			name:   "CRDsOnly",
+			filter: []schema.GroupKind{{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}},
+			want:   []string{"crd-a", "crd-b"},
+		},
+		{
+			name: "PackageObjectsOnly",
+			filter: []schema.GroupKind{
+				{Group: "packages.crossplane.io", Kind: "Package"},
+				{Group: "packages.crossplane.io", Kind: "StackDefinition"},
+			},
+			want: []string{"package", "stackdefinition"},
+		},
+		{
+			name:   "NoneMatch",
+			filter: []schema.GroupKind{{Group: "", Kind: "ConfigMap"}},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterForEstablishment(objs, tt.filter)
+			if diff := cmp.Diff(tt.want, names(got)); diff != "" {
+				t.Errorf("filterForEstablishment(): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterForCapabilities(t *testing.T) {
+	obj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+	names := func(objs []*unstructured.Unstructured) []string {
+		n := make([]string, len(objs))
+		for i := range objs {
+			n[i] = objs[i].GetName()
+		}
+		return n
+	}
+
+	converted := obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "converted")
+	_ = unstructured.SetNestedField(converted.Object, "Webhook", "spec", "conversion", "strategy")
+	objs := []*unstructured.Unstructured{
+		obj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "crd"),
+		converted,
+		obj("packages.crossplane.io/v1alpha1", "Package", "package"),
+	}
+
+	tests := []struct {
+		name         string
+		reason       string
+		capabilities []string
+		want         []string
+	}{
+		{
+			name:   "NoCapabilities",
+			reason: "A package that declares no capabilities should have all of its objects established",
+			want:   []string{"crd", "converted", "package"},
+		},
+		{
+			name:         "OtherCapabilities",
+			reason:       "CRDs that use webhook conversion should not be established unless the package declares the capability",
+			capabilities: []string{"other"},
+			want:         []string{"crd", "package"},
+		},
+		{
+			name:         "ConversionWebhooks",
+			reason:       "CRDs that use webhook conversion should be established if the package declares the capability",
+			capabilities: []string{v1alpha1.CapabilityConversionWebhooks},
+			want:         []string{"crd", "converted", "package"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jc := &packageInstallJobCompleter{log: logging.NewNopLogger()}
+			got := jc.filterForCapabilities(objs, &v1alpha1.AppMetadataSpec{Capabilities: tt.capabilities})
+			if diff := cmp.Diff(tt.want, names(got)); diff != "" {
+				t.Errorf("\n%s\nfilterForCapabilities(): -want, +got:\n%s", tt.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPruneStaleObjects(t *testing.T) {
+	labels := packages.ParentLabels(packageInstallResource())
+	pkg := func(name string, l map[string]string) *v1alpha1.Package {
+		return &v1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: l}}
+	}
+	current := unstructuredObj(packageRaw("crossplane/sample-package:latest"),
+		withUnstructuredObjLabels(labels),
+		withUnstructuredObjNamespacedName(types.NamespacedName{Namespace: namespace, Name: resourceName}),
+	)
+
+	tests := []struct {
+		name     string
+		client   client.Client
+		want     []string
+		unwanted []string
+		wantErr  error
+	}{
+		{
+			name: "FailedList",
+			client: &test.MockClient{
+				MockList: test.NewMockListFn(errBoom),
+			},
+			wantErr: errors.Wrap(errBoom, "failed to list stack definitions to prune"),
+		},
+		{
+			name: "FailedDelete",
+			client: func() client.Client {
+				f := fake.NewFakeClient(pkg("stale", labels))
+				return &test.MockClient{
+					MockList:   f.List,
+					MockDelete: test.NewMockDeleteFn(errBoom),
+				}
+			}(),
+			wantErr: errors.Wrap(errBoom, "failed to prune stale object"),
+		},
+		{
+			name:     "PruneStaleObjects",
+			client:   fake.NewFakeClient(pkg(resourceName, labels), pkg("stale", labels), pkg("unrelated", nil)),
+			want:     []string{resourceName, "unrelated"},
+			unwanted: []string{"stale"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jc := &packageInstallJobCompleter{client: tt.client, log: logging.NewNopLogger()}
+			err := jc.pruneStaleObjects(context.Background(), packageInstallResource(), []*unstructured.Unstructured{current})
+			if diff := cmp.Diff(tt.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("pruneStaleObjects(): -want error, +got error:\n%s", diff)
+			}
+
+			for _, name := range tt.want {
+				if err := tt.client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, &v1alpha1.Package{}); err != nil {
+					t.Errorf("pruneStaleObjects(): wanted Package %s to be kept: %s", name, err)
+				}
+			}
+			for _, name := range tt.unwanted {
+				if err := tt.client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, &v1alpha1.Package{}); !kerrors.IsNotFound(err) {
+					t.Errorf("pruneStaleObjects(): wanted Package %s to be pruned, got: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestControllerSchedulingSetter(t *testing.T) {
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "pool",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"providers"},
+				}},
+			}},
+		},
+	}}
+	toleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "providers", Effect: corev1.TaintEffectNoSchedule}
+
+	withPodSpec := func(ps corev1.PodSpec) *v1alpha1.PackageSpec {
+		return &v1alpha1.PackageSpec{Controller: v1alpha1.ControllerSpec{
+			Deployment: &v1alpha1.ControllerDeployment{Spec: apps.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{Spec: ps},
+			}},
+		}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      v1alpha1.SchedulingOptions
+		spec   *v1alpha1.PackageSpec
+		want   *v1alpha1.PackageSpec
+	}{
+		"NoDeployment": {
+			reason: "A package without a controller deployment should be unchanged",
+			o:      v1alpha1.SchedulingOptions{NodeSelector: map[string]string{"pool": "providers"}},
+			spec:   &v1alpha1.PackageSpec{},
+			want:   &v1alpha1.PackageSpec{},
+		},
+		"NoOptions": {
+			reason: "A controller deployment should be unchanged when no scheduling options are supplied",
+			spec:   withPodSpec(corev1.PodSpec{NodeSelector: map[string]string{"disk": "ssd"}}),
+			want:   withPodSpec(corev1.PodSpec{NodeSelector: map[string]string{"disk": "ssd"}}),
+		},
+		"Propagated": {
+			reason: "Scheduling options should be applied to the pod spec of the controller deployment",
+			o: v1alpha1.SchedulingOptions{
+				NodeSelector: map[string]string{"pool": "providers"},
+				Tolerations:  []corev1.Toleration{toleration},
+				Affinity:     affinity,
+			},
+			spec: withPodSpec(corev1.PodSpec{
+				NodeSelector: map[string]string{"disk": "ssd"},
+				Tolerations:  []corev1.Toleration{{Key: "existing", Operator: corev1.TolerationOpExists}},
+				Affinity:     &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}},
+			}),
+			want: withPodSpec(corev1.PodSpec{
+				NodeSelector: map[string]string{"disk": "ssd", "pool": "providers"},
+				Tolerations:  []corev1.Toleration{{Key: "existing", Operator: corev1.TolerationOpExists}, toleration},
+				Affinity:     affinity,
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := controllerSchedulingSetter(tc.o)(tc.spec); err != nil {
+				t.Fatalf("\n%s\ncontrollerSchedulingSetter(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, tc.spec); diff != "" {
+				t.Errorf("\n%s\ncontrollerSchedulingSetter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestControllerProbeSetter(t *testing.T) {
+	packaged := &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}}}
+	custom := &corev1.Probe{
+		Handler:          corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/livez", Port: intstr.FromInt(9090)}},
+		FailureThreshold: 5,
+	}
+
+	withProbes := func(liveness, readiness *corev1.Probe) *v1alpha1.PackageSpec {
+		return &v1alpha1.PackageSpec{Controller: v1alpha1.ControllerSpec{
+			Deployment: &v1alpha1.ControllerDeployment{Spec: apps.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "controller", LivenessProbe: liveness, ReadinessProbe: readiness}},
+				}},
+			}},
+		}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      v1alpha1.ProbeOptions
+		want   *v1alpha1.PackageSpec
+	}{
+		"NoOptions": {
+			reason: "The probes specified by the package should be kept when no probes are supplied",
+			want:   withProbes(packaged, packaged),
+		},
+		"LivenessProbe": {
+			reason: "Only the liveness probe should be replaced when only it is supplied",
+			o:      v1alpha1.ProbeOptions{LivenessProbe: custom},
+			want:   withProbes(custom, packaged),
+		},
+		"BothProbes": {
+			reason: "Both probes should be replaced when both are supplied",
+			o:      v1alpha1.ProbeOptions{LivenessProbe: custom, ReadinessProbe: custom},
+			want:   withProbes(custom, custom),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec := withProbes(packaged, packaged)
+			if err := controllerProbeSetter(tc.o)(spec); err != nil {
+				t.Fatalf("\n%s\ncontrollerProbeSetter(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, spec); diff != "" {
+				t.Errorf("\n%s\ncontrollerProbeSetter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestControllerReplicaSetter(t *testing.T) {
+	one, three := int32(1), int32(3)
+	disabled := false
+	elect := corev1.EnvVar{Name: packages.LeaderElectionEnv, Value: "true"}
+
+	withReplicas := func(r *int32, env ...corev1.EnvVar) *v1alpha1.PackageSpec {
+		return &v1alpha1.PackageSpec{Controller: v1alpha1.ControllerSpec{
+			Deployment: &v1alpha1.ControllerDeployment{Spec: apps.DeploymentSpec{
+				Replicas: r,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "controller", Env: env}},
+				}},
+			}},
+		}}
+	}
+
+	type want struct {
+		spec   *v1alpha1.PackageSpec
+		warned bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      v1alpha1.ReplicaOptions
+		spec   *v1alpha1.PackageSpec
+		want   want
+	}{
+		"SingleReplica": {
+			reason: "A controller that runs a single replica should not be told to elect a leader",
+			spec:   withReplicas(&one),
+			want:   want{spec: withReplicas(&one)},
+		},
+		"PackagedReplicas": {
+			reason: "A controller that its package says runs many replicas should be told to elect a leader",
+			spec:   withReplicas(&three),
+			want:   want{spec: withReplicas(&three, elect)},
+		},
+		"OverriddenReplicas": {
+			reason: "A controller that is overridden to run many replicas should be told to elect a leader",
+			o:      v1alpha1.ReplicaOptions{Replicas: &three},
+			spec:   withReplicas(nil),
+			want:   want{spec: withReplicas(&three, elect)},
+		},
+		"LeaderElectionDisabled": {
+			reason: "A warning should be emitted when a controller runs many replicas without leader election",
+			o:      v1alpha1.ReplicaOptions{Replicas: &three, LeaderElection: &disabled},
+			spec:   withReplicas(nil),
+			want:   want{spec: withReplicas(&three), warned: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warned := false
+			warn := func(_ string, _ ...interface{}) { warned = true }
+			if err := controllerReplicaSetter(tc.o, warn)(tc.spec); err != nil {
+				t.Fatalf("\n%s\ncontrollerReplicaSetter(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.spec, tc.spec); diff != "" {
+				t.Errorf("\n%s\ncontrollerReplicaSetter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warned, warned); diff != "" {
+				t.Errorf("\n%s\ncontrollerReplicaSetter(...): -want warning, +got warning:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestControllerRuntimeSetter(t *testing.T) {
+	withDeployment := func() *v1alpha1.PackageSpec {
+		return &v1alpha1.PackageSpec{Controller: v1alpha1.ControllerSpec{
+			Deployment: &v1alpha1.ControllerDeployment{Name: "cool-controller"},
+		}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		r      v1alpha1.ControllerRuntime
+		want   *v1alpha1.PackageSpec
+	}{
+		"Default": {
+			reason: "The controller deployment should be kept when no runtime is specified",
+			want:   withDeployment(),
+		},
+		"Deployment": {
+			reason: "The controller deployment should be kept when the Deployment runtime is specified",
+			r:      v1alpha1.ControllerRuntimeDeployment,
+			want:   withDeployment(),
+		},
+		"External": {
+			reason: "The controller deployment should be removed when the External runtime is specified",
+			r:      v1alpha1.ControllerRuntimeExternal,
+			want:   &v1alpha1.PackageSpec{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec := withDeployment()
+			if err := controllerRuntimeSetter(tc.r)(spec); err != nil {
+				t.Fatalf("\n%s\ncontrollerRuntimeSetter(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, spec); diff != "" {
+				t.Errorf("\n%s\ncontrollerRuntimeSetter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPackageContentsContainer(t *testing.T) {
+	mounts := []corev1.VolumeMount{{Name: packageContentsVolumeName, MountPath: "/ext-pkg"}}
+
+	cases := map[string]struct {
+		reason string
+		p      buildInstallJobParams
+		want   corev1.Container
+	}{
+		"Image": {
+			reason: "The contents of a package image should be copied from the image",
+			p: buildInstallJobParams{
+				img:                      "cool/package:v0.1.0",
+				imagePullPolicy:          corev1.PullIfNotPresent,
+				packageManagerImage:      packagePackageImage,
+				packageManagerPullPolicy: corev1.PullAlways,
+			},
+			want: corev1.Container{
+				Name:            "package-copy-to-volume",
+				Image:           "cool/package:v0.1.0",
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"cp", "-R", registryDirName, "/ext-pkg/"},
+				VolumeMounts:    mounts,
+			},
+		},
+		"Tarball": {
+			reason: "The contents of a package tarball should be fetched by the package manager",
+			p: buildInstallJobParams{
+				img:                      "https://example.org/cool.tgz",
+				imagePullPolicy:          corev1.PullIfNotPresent,
+				packageManagerImage:      packagePackageImage,
+				packageManagerPullPolicy: corev1.PullAlways,
+				packageSHA256:            "c00l",
+			},
+			want: corev1.Container{
+				Name:            "package-fetch-to-volume",
+				Image:           packagePackageImage,
+				ImagePullPolicy: corev1.PullAlways,
+				Args:            []string{"package", "fetch", "--url=https://example.org/cool.tgz", "--dir=/ext-pkg", "--sha256=c00l"},
+				VolumeMounts:    mounts,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := packageContentsContainer(tc.p)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\npackageContentsContainer(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBuildInstallJobResources(t *testing.T) {
+	rr := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+
+	j := buildInstallJob(buildInstallJobParams{img: "cool/package:v0.1.0", resources: rr})
+
+	for _, c := range append(j.Spec.Template.Spec.InitContainers, j.Spec.Template.Spec.Containers...) {
+		if diff := cmp.Diff(rr, c.Resources); diff != "" {
+			t.Errorf("buildInstallJob(...): container %s: -want resources, +got resources:\n%s", c.Name, diff)
+		}
+	}
+}
+
+func TestControllerPullSetter(t *testing.T) {
+	spec := &v1alpha1.PackageSpec{Controller: v1alpha1.ControllerSpec{
+		Deployment: &v1alpha1.ControllerDeployment{Name: "cool-controller"},
+	}}
+	spec.Controller.Deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init"}}
+	spec.Controller.Deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "controller", ImagePullPolicy: corev1.PullAlways}}
+
+	secrets := []corev1.LocalObjectReference{{Name: "cool-secret"}}
+	if err := controllerPullSetter(corev1.PullIfNotPresent, secrets)(spec); err != nil {
+		t.Fatalf("controllerPullSetter(...): %s", err)
+	}
+
+	want := corev1.PodSpec{
+		ImagePullSecrets: secrets,
+		InitContainers:   []corev1.Container{{Name: "init", ImagePullPolicy: corev1.PullIfNotPresent}},
+		Containers:       []corev1.Container{{Name: "controller", ImagePullPolicy: corev1.PullIfNotPresent}},
+	}
+	if diff := cmp.Diff(want, spec.Controller.Deployment.Spec.Template.Spec); diff != "" {
+		t.Errorf("controllerPullSetter(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		result reconcile.Result
+		err    error
+		ext    *v1alpha1.PackageInstall
+		job    *batchv1.Job
+	}
+
+	noJobs := func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+		return kerrors.NewNotFound(schema.GroupResource{Group: "batch", Resource: "Job"}, key.String())
+	}
+
+	tests := []struct {
+		name    string
+		handler *packageInstallHandler
+		want    want
+	}{
+		{
+			name: "CreateInstallJob",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+				},
+				hostKube: &test.MockClient{
+					MockGet:    noJobs,
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return nil },
+				},
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:          packageInstallResource(),
+				log:          logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{
+						Name:       resourceName,
+						Namespace:  namespace,
+						Kind:       "Job",
+						APIVersion: batchv1.SchemeGroupVersion.String(),
+					}),
+				),
+			},
+		},
+		{
+			name: "CreateInstallJobForcedPullPolicy",
+			handler: &packageInstallHandler{
+				forceImagePullPolicy: string(corev1.PullAlways),
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+				},
+				hostKube:     fake.NewFakeClient(),
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:          packageInstallResource(withImagePullPolicy(corev1.PullNever)),
+				log:          logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withImagePullPolicy(corev1.PullNever),
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{
+						Name:       resourceName,
+						Namespace:  namespace,
+						Kind:       "Job",
+						APIVersion: batchv1.SchemeGroupVersion.String(),
+					}),
+				),
+				job: job(
+					withJobExpectations(),
+					withJobPullPolicy(corev1.PullAlways),
+				),
+			},
+		},
+		{
+			name: "CreateInstallJobDefaultPullPolicy",
+			handler: &packageInstallHandler{
+				defaultImagePullPolicy: string(corev1.PullIfNotPresent),
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+				},
+				hostKube:     fake.NewFakeClient(),
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:          packageInstallResource(),
+				log:          logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{
+						Name:       resourceName,
+						Namespace:  namespace,
+						Kind:       "Job",
+						APIVersion: batchv1.SchemeGroupVersion.String(),
+					}),
+				),
+				job: job(
+					withJobExpectations(),
+					withJobPullPolicy(corev1.PullIfNotPresent),
+				),
+			},
+		},
+		{
+			name: "CreateInstallJobHosted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+				},
+				hostKube: &test.MockClient{
+					MockGet:    noJobs,
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return nil },
+					MockList:   func(ctx context.Context, obj runtime.Object, _ ...client.ListOption) error { return nil },
+				},
+				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
+				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:             packageInstallResource(),
+				log:             logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{
+						Name:       fmt.Sprintf("%s.%s", namespace, resourceName),
+						Namespace:  hostControllerNamespace,
+						Kind:       "Job",
+						APIVersion: batchv1.SchemeGroupVersion.String(),
+					}),
+				),
+			},
+		},
+		{
+			name: "CreateInstallJobHostedWithPullSecrets",
+			handler: &packageInstallHandler{
+				kube: fake.NewFakeClient(
+					secret("secret", namespace),
+					packageInstallResource(withImagePullSecrets([]corev1.LocalObjectReference{{Name: "secret"}})),
+				),
+				hostKube:        fake.NewFakeClient(),
+				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
 				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
 				ext:             packageInstallResource(withImagePullSecrets([]corev1.LocalObjectReference{{Name: "secret"}})),
 				log:             logging.NewNopLogger(),
@@ -1071,31 +2422,163 @@ func TestCreate(t *testing.T) {
 			},
 		},
 		{
-			name: "ExistingInstallJobHosted",
+			name: "ExistingInstallJobHosted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						return nil
+					},
+				},
+				hostKube: func() client.Client {
+					c := fake.NewFakeClient(job(withJobName(types.NamespacedName{Name: fmt.Sprintf("%s.%s", namespace, resourceName), Namespace: hostControllerNamespace})))
+					return &test.MockClient{
+						MockList:   c.List,
+						MockCreate: test.NewMockCreateFn(errBoom),
+						MockGet:    c.Get,
+					}
+				}(),
+				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
+				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:             packageInstallResource(),
+				log:             logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{
+						Name:       fmt.Sprintf("%s.%s", namespace, resourceName),
+						Namespace:  hostControllerNamespace,
+						Kind:       "Job",
+						APIVersion: batchv1.SchemeGroupVersion.String(),
+					}),
+				),
+			},
+		},
+		{
+			name: "HandleFailedInstallJobHosted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						return nil
+					},
+				},
+				hostKube: &test.MockClient{
+					MockGet:    noJobs,
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return errBoom },
+				},
+				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
+				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:             packageInstallResource(),
+				log:             logging.NewNopLogger(),
+			},
+			want: want{
+				result: resultRequeue,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileError(errBoom)),
+					withInstallJob(nil),
+				),
+			},
+		},
+		{
+			name: "FailedToGetInstallJob",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						return nil
+					},
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+						return errBoom
+					},
+				},
+				ext: packageInstallResource(
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: resultRequeue,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileError(errBoom)),
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
+				),
+			},
+		},
+		{
+			name: "InstallJobNotCompleted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+						return nil
+					},
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						return nil
+					},
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+						// GET Job returns an uncompleted job
+						return nil
+					},
+				},
+				ext: packageInstallResource(
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				err:    nil,
+				ext: packageInstallResource(
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
+				),
+			},
+		},
+		{
+			name: "HandleSuccessfulInstallJob",
 			handler: &packageInstallHandler{
 				kube: &test.MockClient{
 					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
 						return nil
 					},
-					MockStatusPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
+					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
 						return nil
 					},
-					MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+						// GET Job returns a successful/completed job
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
 						return nil
 					},
 				},
-				hostKube: func() client.Client {
-					c := fake.NewFakeClient(job(withJobName(types.NamespacedName{Name: fmt.Sprintf("%s.%s", namespace, resourceName), Namespace: hostControllerNamespace})))
-					return &test.MockClient{
-						MockList:   c.List,
-						MockCreate: test.NewMockCreateFn(errBoom),
-						MockGet:    c.Get,
-					}
-				}(),
-				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
-				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
-				ext:             packageInstallResource(),
-				log:             logging.NewNopLogger(),
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error { return nil },
+				},
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
+				ext: packageInstallResource(
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
+				log: logging.NewNopLogger(),
 			},
 			want: want{
 				result: requeueOnSuccess,
@@ -1103,17 +2586,12 @@ func TestCreate(t *testing.T) {
 				ext: packageInstallResource(
 					withFinalizers(installFinalizer),
 					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
-					withInstallJob(&corev1.ObjectReference{
-						Name:       fmt.Sprintf("%s.%s", namespace, resourceName),
-						Namespace:  hostControllerNamespace,
-						Kind:       "Job",
-						APIVersion: batchv1.SchemeGroupVersion.String(),
-					}),
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
 				),
 			},
 		},
 		{
-			name: "HandleFailedInstallJobHosted",
+			name: "PreActivationHookCreated",
 			handler: &packageInstallHandler{
 				kube: &test.MockClient{
 					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
@@ -1124,26 +2602,52 @@ func TestCreate(t *testing.T) {
 					},
 				},
 				hostKube: &test.MockClient{
-					MockGet:    noJobs,
-					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error { return errBoom },
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+						// GET Job returns a completed install job, but no hook job
+						if key.Name == resourceName+preActivationHookSuffix {
+							return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+						}
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+						return nil
+					},
+					MockCreate: func(ctx context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+						j := obj.(*batchv1.Job)
+						if j.GetName() != resourceName+preActivationHookSuffix {
+							return errors.Errorf("unexpected job %s", j.GetName())
+						}
+						if j.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+							return errors.Errorf("unexpected restart policy %s", j.Spec.Template.Spec.RestartPolicy)
+						}
+						if img := j.Spec.Template.Spec.Containers[0].Image; img != "cool/hook:v1" {
+							return errors.Errorf("unexpected image %s", img)
+						}
+						return nil
+					},
 				},
-				hostAwareConfig: &hosted.Config{HostControllerNamespace: hostControllerNamespace},
-				executorInfo:    &packages.ExecutorInfo{Image: packagePackageImage},
-				ext:             packageInstallResource(),
-				log:             logging.NewNopLogger(),
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error {
+						return errors.New("package should not be activated before its hook completes")
+					},
+				},
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
+				ext: packageInstallResource(
+					withPreActivationHook(&v1alpha1.PreActivationHook{Image: "cool/hook:v1"}),
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
+				log: logging.NewNopLogger(),
 			},
 			want: want{
-				result: resultRequeue,
+				result: requeueOnSuccess,
 				err:    nil,
 				ext: packageInstallResource(
 					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileError(errBoom)),
-					withInstallJob(nil),
+					withPreActivationHook(&v1alpha1.PreActivationHook{Image: "cool/hook:v1"}),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
 				),
 			},
 		},
 		{
-			name: "FailedToGetInstallJob",
+			name: "PreActivationHookFailed",
 			handler: &packageInstallHandler{
 				kube: &test.MockClient{
 					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
@@ -1155,10 +2659,23 @@ func TestCreate(t *testing.T) {
 				},
 				hostKube: &test.MockClient{
 					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
-						return errBoom
+						// GET Job returns a completed install job and a failed hook job
+						if key.Name == resourceName+preActivationHookSuffix {
+							*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobFailed, "mock hook failure message")))
+							return nil
+						}
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+						return nil
+					},
+				},
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error {
+						return errors.New("package should not be activated when its hook fails")
 					},
 				},
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
 				ext: packageInstallResource(
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
 					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
 				log: logging.NewNopLogger(),
 			},
@@ -1167,13 +2684,17 @@ func TestCreate(t *testing.T) {
 				err:    nil,
 				ext: packageInstallResource(
 					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileError(errBoom)),
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
+					withConditions(
+						runtimev1alpha1.Creating(),
+						runtimev1alpha1.ReconcileError(errors.Errorf(errFmtHookFailed, "mock hook failure message")),
+					),
 					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
 				),
 			},
 		},
 		{
-			name: "InstallJobNotCompleted",
+			name: "PreActivationHookCompleted",
 			handler: &packageInstallHandler{
 				kube: &test.MockClient{
 					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
@@ -1185,11 +2706,23 @@ func TestCreate(t *testing.T) {
 				},
 				hostKube: &test.MockClient{
 					MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
-						// GET Job returns an uncompleted job
+						// GET Job returns completed install and hook jobs
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+						return nil
+					},
+					MockDelete: func(ctx context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+						if n := obj.(*batchv1.Job).GetName(); n != resourceName+preActivationHookSuffix {
+							return errors.Errorf("unexpected job %s", n)
+						}
 						return nil
 					},
 				},
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error { return nil },
+				},
+				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
 				ext: packageInstallResource(
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
 					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace})),
 				log: logging.NewNopLogger(),
 			},
@@ -1198,13 +2731,14 @@ func TestCreate(t *testing.T) {
 				err:    nil,
 				ext: packageInstallResource(
 					withFinalizers(installFinalizer),
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
 					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
 					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
 				),
 			},
 		},
 		{
-			name: "HandleSuccessfulInstallJob",
+			name: "HandleInstallJobPermanentError",
 			handler: &packageInstallHandler{
 				kube: &test.MockClient{
 					MockPatch: func(_ context.Context, obj runtime.Object, patch client.Patch, _ ...client.PatchOption) error {
@@ -1222,7 +2756,9 @@ func TestCreate(t *testing.T) {
 					},
 				},
 				jobCompleter: &mockJobCompleter{
-					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error { return nil },
+					MockHandleJobCompletion: func(ctx context.Context, i v1alpha1.PackageInstaller, job *batchv1.Job) error {
+						return errors.Wrap(permanent(errBoom), "invalid package")
+					},
 				},
 				executorInfo: &packages.ExecutorInfo{Image: packagePackageImage},
 				ext: packageInstallResource(
@@ -1230,11 +2766,14 @@ func TestCreate(t *testing.T) {
 				log: logging.NewNopLogger(),
 			},
 			want: want{
-				result: requeueOnSuccess,
+				result: requeuePermanent,
 				err:    nil,
 				ext: packageInstallResource(
 					withFinalizers(installFinalizer),
-					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+					withConditions(
+						runtimev1alpha1.Creating(),
+						runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, "invalid package")),
+					),
 					withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}),
 				),
 			},
@@ -1318,6 +2857,153 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestEstablishCooldown(t *testing.T) {
+	const cooldown = time.Minute
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(time.Now().Add(d))
+		return &t
+	}
+
+	type want struct {
+		called bool
+		result func(reconcile.Result) bool
+		next   func(*metav1.Time) bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		next   *metav1.Time
+		err    error
+		want   want
+	}{
+		"TransientFailure": {
+			reason: "A transient failure to establish should record when to next attempt establishment, and requeue then",
+			err:    errBoom,
+			want: want{
+				called: true,
+				result: func(r reconcile.Result) bool { return r == reconcile.Result{RequeueAfter: cooldown} },
+				next: func(n *metav1.Time) bool {
+					return n != nil && time.Until(n.Time) > 0 && time.Until(n.Time) <= cooldown
+				},
+			},
+		},
+		"PermanentFailure": {
+			reason: "A permanent failure to establish should not be subject to the cooldown",
+			err:    permanent(errBoom),
+			want: want{
+				called: true,
+				result: func(r reconcile.Result) bool { return r == requeuePermanent },
+				next:   func(n *metav1.Time) bool { return n == nil },
+			},
+		},
+		"CoolingDown": {
+			reason: "Establishment should not be attempted until the next attempt time has passed",
+			next:   at(time.Hour),
+			want: want{
+				result: func(r reconcile.Result) bool { return r.RequeueAfter > 59*time.Minute && r.RequeueAfter <= time.Hour },
+				next:   func(n *metav1.Time) bool { return n != nil },
+			},
+		},
+		"CooledDown": {
+			reason: "Establishment should be attempted once the next attempt time has passed, and the time cleared on success",
+			next:   at(-time.Minute),
+			want: want{
+				called: true,
+				result: func(r reconcile.Result) bool { return r == requeueOnSuccess },
+				next:   func(n *metav1.Time) bool { return n == nil },
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			ext := packageInstallResource(withInstallJob(&corev1.ObjectReference{Name: resourceName, Namespace: namespace}))
+			ext.SetNextEstablishAttempt(tc.next)
+			h := &packageInstallHandler{
+				kube: &test.MockClient{
+					MockPatch:        test.NewMockPatchFn(nil),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+						return nil
+					},
+				},
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(_ context.Context, _ v1alpha1.PackageInstaller, _ *batchv1.Job) error {
+						called = true
+						return tc.err
+					},
+				},
+				executorInfo:      &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:               ext,
+				establishCooldown: cooldown,
+				log:               logging.NewNopLogger(),
+			}
+
+			result, err := h.create(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\ncreate(): %s", tc.reason, err)
+			}
+			if called != tc.want.called {
+				t.Errorf("\n%s\ncreate(): establishment attempted: want %t, got %t", tc.reason, tc.want.called, called)
+			}
+			if !tc.want.result(result) {
+				t.Errorf("\n%s\ncreate(): unexpected result: %+v", tc.reason, result)
+			}
+			if next := ext.GetNextEstablishAttempt(); !tc.want.next(next) {
+				t.Errorf("\n%s\ncreate(): unexpected next establish attempt: %v", tc.reason, next)
+			}
+		})
+	}
+}
+
+func TestImagePullPolicy(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		i      v1alpha1.PackageInstaller
+		force  string
+		def    string
+		want   corev1.PullPolicy
+	}{
+		"Forced": {
+			reason: "A forced pull policy should override the one the package specifies",
+			i:      packageInstallResource(withImagePullPolicy(corev1.PullNever)),
+			force:  string(corev1.PullAlways),
+			def:    string(corev1.PullIfNotPresent),
+			want:   corev1.PullAlways,
+		},
+		"Specified": {
+			reason: "The pull policy the package specifies should override the default",
+			i:      packageInstallResource(withImagePullPolicy(corev1.PullNever)),
+			def:    string(corev1.PullIfNotPresent),
+			want:   corev1.PullNever,
+		},
+		"Default": {
+			reason: "The default pull policy should be used when the package does not specify one",
+			i:      packageInstallResource(),
+			def:    string(corev1.PullIfNotPresent),
+			want:   corev1.PullIfNotPresent,
+		},
+		"Unspecified": {
+			reason: "No pull policy should be used when none is forced, specified, or defaulted",
+			i:      packageInstallResource(),
+			want:   "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := imagePullPolicy(tc.i, tc.force, tc.def)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nimagePullPolicy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestCreateJobOutputObject(t *testing.T) {
 	wantedParentLabels := map[string]string{
 		packages.LabelParentGroup:     "packages.crossplane.io",
@@ -1328,8 +3014,9 @@ func TestCreateJobOutputObject(t *testing.T) {
 	}
 
 	type want struct {
-		err error
-		obj *unstructured.Unstructured
+		err         error
+		obj         *unstructured.Unstructured
+		established *runtimev1alpha1.Condition
 	}
 
 	tests := []struct {
@@ -1407,6 +3094,20 @@ func TestCreateJobOutputObject(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "CreateSuccessfulCRDWithExternalConversionWebhook",
+			jobCompleter: &packageInstallJobCompleter{
+				client: fake.NewFakeClient(),
+				log:    logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw, unstructuredAsCRD(withCRDExternalConversionWebhook("https://convert.example.org", []byte("cool-ca")))),
+			want: want{
+				err: nil,
+				obj: unstructuredObj(crdRaw, unstructuredAsCRD(withCRDExternalConversionWebhook("https://convert.example.org", []byte("cool-ca")))),
+			},
+		},
 		{
 			name: "CreateSuccessfulStackDefinition",
 			jobCompleter: &packageInstallJobCompleter{
@@ -1531,6 +3232,85 @@ func TestCreateJobOutputObject(t *testing.T) {
 				obj: nil,
 			},
 		},
+		{
+			name: "FailedCRDTerminating",
+			jobCompleter: &packageInstallJobCompleter{
+				client: func() client.Client {
+					crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"), withCRDCondition(apiextensions.Terminating, "instances in progress"))
+					return fake.NewFakeClient(&crd)
+				}(),
+				log: logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw),
+			want: want{
+				err: errors.Wrapf(errors.New("failed due to pending deletion of existing crd"), "can not update existing CRD %s from job %s", "mytypes.samples.upbound.io", "cool-packageinstall"),
+				established: func() *runtimev1alpha1.Condition {
+					c := v1alpha1.CRDTerminating("mytypes.samples.upbound.io")
+					return &c
+				}(),
+			},
+		},
+		{
+			name: "FailedCRDStorageVersionChange",
+			jobCompleter: &packageInstallJobCompleter{
+				client: func() client.Client {
+					crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"), withCRDVersion("v1alpha1"), withCRDVersion("v1beta1"), withCRDStorageVersion("v1alpha1"))
+					return fake.NewFakeClient(&crd)
+				}(),
+				log: logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("v1alpha1"), withCRDVersion("v1beta1"), withCRDStorageVersion("v1beta1"))),
+			want: want{
+				err: errors.Wrapf(errors.New(errStorageVersionChange), "can not update existing CRD %s from job %s", "mytypes.samples.upbound.io", "cool-packageinstall"),
+				established: func() *runtimev1alpha1.Condition {
+					c := v1alpha1.StorageVersionChange("mytypes.samples.upbound.io", "v1alpha1", "v1beta1")
+					return &c
+				}(),
+			},
+		},
+		{
+			name: "SuccessUpdatingCRDWithUnchangedStorageVersion",
+			jobCompleter: &packageInstallJobCompleter{
+				client: func() client.Client {
+					crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"), withCRDVersion("v1alpha1"), withCRDStorageVersion("v1alpha1"))
+					// See the note on SuccessUpdatingCRD below.
+					crd.SetResourceVersion("1")
+					return fake.NewFakeClient(&crd)
+				}(),
+				log: logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("v1alpha1"), withCRDVersion("v1beta1"), withCRDStorageVersion("v1alpha1"))),
+			want: want{
+				obj: unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("v1alpha1"), withCRDVersion("v1beta1"), withCRDStorageVersion("v1alpha1"))),
+			},
+		},
+		{
+			name: "SuccessUpdatingNonStructuralCRD",
+			jobCompleter: &packageInstallJobCompleter{
+				client: func() client.Client {
+					crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"), withCRDCondition(apiextensions.NonStructuralSchema, "spec.validation: Required value"))
+					// See the note on SuccessUpdatingCRD below.
+					crd.SetResourceVersion("1")
+					return fake.NewFakeClient(&crd)
+				}(),
+				log: logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("new"))),
+			want: want{
+				established: func() *runtimev1alpha1.Condition {
+					c := v1alpha1.CRDNonStructural("mytypes.samples.upbound.io", "spec.validation: Required value")
+					return &c
+				}(),
+			},
+		},
 		{
 			name: "FailedIncompatibleCRDExists",
 			jobCompleter: &packageInstallJobCompleter{
@@ -1572,6 +3352,55 @@ func TestCreateJobOutputObject(t *testing.T) {
 				obj: unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("new"), withCRDLabels(map[string]string{"foo": "bar"}))),
 			},
 		},
+		{
+			name: "SuccessSkippingUnchangedCRD",
+			jobCompleter: &packageInstallJobCompleter{
+				client: &test.MockClient{
+					MockCreate: func(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+						return kerrors.NewAlreadyExists(schema.GroupResource{}, crdName)
+					},
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						existing, err := convertToCRD(unstructuredObj(crdRaw))
+						if err != nil {
+							return err
+						}
+						*obj.(*apiextensions.CustomResourceDefinition) = *existing
+						return nil
+					},
+					MockUpdate: test.NewMockUpdateFn(errors.New("unexpected update of unchanged CRD")),
+					MockPatch:  test.NewMockPatchFn(errors.New("unexpected patch of unchanged CRD")),
+				},
+				log: logging.NewNopLogger(),
+			},
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw),
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "SuccessUpdatingCRDWithImmutableFields",
+			jobCompleter: func() *packageInstallJobCompleter {
+				crd := crd(withCRDGroupKind("samples.upbound.io", "Mytype"), withCRDPreserveUnknownFields(false))
+				crd.SetResourceVersion("1")
+				jc := &packageInstallJobCompleter{
+					client: fake.NewFakeClient(&crd),
+					log:    logging.NewNopLogger(),
+				}
+				WithImmutableFields(map[schema.GroupKind][]string{
+					{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: {"spec.preserveUnknownFields"},
+				})(jc)
+				return jc
+			}(),
+			packageInstaller: packageInstallResource(),
+			job:              job(),
+			obj:              unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("new"))),
+			want: want{
+				err: nil,
+				obj: unstructuredObj(crdRaw, unstructuredAsCRD(withCRDVersion("new"), withCRDPreserveUnknownFields(false))),
+			},
+		},
 	}
 
 	ctx := context.Background()
@@ -1580,7 +3409,7 @@ func TestCreateJobOutputObject(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGomegaWithT(t)
 
-			gotErr := tt.jobCompleter.createJobOutputObject(ctx, tt.obj, tt.packageInstaller, tt.job)
+			_, gotErr := tt.jobCompleter.createJobOutputObject(ctx, tt.obj, tt.packageInstaller, tt.job)
 
 			if diff := cmp.Diff(tt.want.err, gotErr, test.EquateErrors()); diff != "" {
 				t.Errorf("createJobOutputObject(): -want error, +got error:\n%s", diff)
@@ -1593,6 +3422,13 @@ func TestCreateJobOutputObject(t *testing.T) {
 				assertKubernetesObject(t, g, got, tt.want.obj, tt.jobCompleter.client)
 			}
 
+			if tt.want.established != nil {
+				got := tt.packageInstaller.Status.GetCondition(v1alpha1.TypeEstablished)
+				if diff := cmp.Diff(*tt.want.established, got, test.EquateConditions()); diff != "" {
+					t.Errorf("createJobOutputObject(): -want established condition, +got:\n%s", diff)
+				}
+			}
+
 		})
 	}
 }
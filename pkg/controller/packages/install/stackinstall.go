@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	runtimeresource "github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -48,12 +49,14 @@ import (
 const (
 	reconcileTimeout      = 1 * time.Minute
 	requeueAfterOnSuccess = 10 * time.Second
+	requeueAfterPermanent = 5 * time.Minute
 	installFinalizer      = "finalizer.packageinstall.crossplane.io"
 )
 
 var (
 	resultRequeue    = reconcile.Result{Requeue: true}
 	requeueOnSuccess = reconcile.Result{RequeueAfter: requeueAfterOnSuccess}
+	requeuePermanent = reconcile.Result{RequeueAfter: requeueAfterPermanent}
 )
 
 // k8sClients holds the clients for Kubernetes
@@ -82,8 +85,11 @@ type Reconciler struct {
 }
 
 // SetupClusterPackageInstall adds a controller that reconciles
-// ClusterPackageInstalls.
-func SetupClusterPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage string) error {
+// ClusterPackageInstalls. The forced image pull policy, if any, overrides that
+// of every ClusterPackageInstall, while the default image pull policy, if any,
+// applies to those that do not specify one. The unpack resource requirements
+// apply to the containers of install jobs.
+func SetupClusterPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage, forceImagePullPolicy, defaultImagePullPolicy string, unpackResources corev1.ResourceRequirements, opts ...JobCompleterOption) error {
 	name := "packages/" + strings.ToLower(v1alpha1.ClusterPackageInstallGroupKind)
 	packinator := func() v1alpha1.PackageInstaller { return &v1alpha1.ClusterPackageInstall{} }
 
@@ -100,6 +106,10 @@ func SetupClusterPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControll
 		return err
 	}
 
+	// Events are recorded by default, but may be overridden by the supplied
+	// options.
+	opts = append([]JobCompleterOption{WithEventRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))}, opts...)
+
 	r := &Reconciler{
 		k8sClients: k8sClients{
 			kube:       mgr.GetClient(),
@@ -108,9 +118,10 @@ func SetupClusterPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControll
 		},
 		hostedConfig:             hc,
 		packinator:               packinator,
-		factory:                  &handlerFactory{},
+		factory:                  &handlerFactory{jobCompleterOptions: opts, defaultImagePullPolicy: defaultImagePullPolicy, unpackResources: unpackResources},
 		executorInfoDiscoverer:   &packages.KubeExecutorInfoDiscoverer{Client: hostKube},
 		templatesControllerImage: tsControllerImage,
+		forceImagePullPolicy:     forceImagePullPolicy,
 		log:                      l.WithValues("controller", name),
 	}
 
@@ -120,8 +131,12 @@ func SetupClusterPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControll
 		Complete(r)
 }
 
-// SetupPackageInstall adds a controller that reconciles PackageInstalls.
-func SetupPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage, forceImagePullPolicy string) error {
+// SetupPackageInstall adds a controller that reconciles PackageInstalls. The
+// forced image pull policy, if any, overrides that of every PackageInstall,
+// while the default image pull policy, if any, applies to those that do not
+// specify one. The unpack resource requirements apply to the containers of
+// install jobs.
+func SetupPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage, forceImagePullPolicy, defaultImagePullPolicy string, unpackResources corev1.ResourceRequirements, opts ...JobCompleterOption) error {
 	name := "packages/" + strings.ToLower(v1alpha1.PackageInstallGroupKind)
 	packinator := func() v1alpha1.PackageInstaller { return &v1alpha1.PackageInstall{} }
 
@@ -138,6 +153,10 @@ func SetupPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNames
 		return err
 	}
 
+	// Events are recorded by default, but may be overridden by the supplied
+	// options.
+	opts = append([]JobCompleterOption{WithEventRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))}, opts...)
+
 	r := &Reconciler{
 		k8sClients: k8sClients{
 			kube:       mgr.GetClient(),
@@ -146,7 +165,7 @@ func SetupPackageInstall(mgr ctrl.Manager, l logging.Logger, hostControllerNames
 		},
 		hostedConfig:             hc,
 		packinator:               packinator,
-		factory:                  &handlerFactory{},
+		factory:                  &handlerFactory{jobCompleterOptions: opts, defaultImagePullPolicy: defaultImagePullPolicy, unpackResources: unpackResources},
 		executorInfoDiscoverer:   &packages.KubeExecutorInfoDiscoverer{Client: hostKube},
 		templatesControllerImage: tsControllerImage,
 		forceImagePullPolicy:     forceImagePullPolicy,
@@ -203,6 +222,19 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return handler.delete(ctx)
 	}
 
+	// A paused PackageInstall is still finalized when it is deleted, but is
+	// otherwise left as it is. We don't requeue; removing the annotation is
+	// an update that will trigger a reconcile.
+	if packages.IsPaused(packageInstaller) {
+		r.log.Debug("Reconciliation is paused", "request", req)
+		if packageInstaller.GetCondition(runtimev1alpha1.TypeSynced).Equal(v1alpha1.ReconcilePaused()) {
+			// We've already reported that we're paused.
+			return reconcile.Result{}, nil
+		}
+		packageInstaller.SetConditions(v1alpha1.ReconcilePaused())
+		return reconcile.Result{}, r.kube.Status().Update(ctx, packageInstaller)
+	}
+
 	return handler.sync(ctx)
 }
 
@@ -224,6 +256,9 @@ type packageInstallHandler struct {
 	ext                      v1alpha1.PackageInstaller
 	templatesControllerImage string
 	forceImagePullPolicy     string
+	defaultImagePullPolicy   string
+	unpackResources          corev1.ResourceRequirements
+	establishCooldown        time.Duration
 
 	log logging.Logger
 }
@@ -233,27 +268,48 @@ type factory interface {
 	newHandler(logging.Logger, v1alpha1.PackageInstaller, k8sClients, *hosted.Config, *packages.ExecutorInfo, string, string) handler
 }
 
-type handlerFactory struct{}
+type handlerFactory struct {
+	jobCompleterOptions []JobCompleterOption
+
+	// defaultImagePullPolicy applies to packages that do not specify an
+	// image pull policy.
+	defaultImagePullPolicy string
+
+	// unpackResources are the resource requirements of install job
+	// containers.
+	unpackResources corev1.ResourceRequirements
+}
 
 func (f *handlerFactory) newHandler(log logging.Logger, ext v1alpha1.PackageInstaller, k8s k8sClients, hostAwareConfig *hosted.Config, ei *packages.ExecutorInfo, templatesControllerImage, forceImagePullPolicy string) handler {
 
-	return &packageInstallHandler{
-		ext:             ext,
-		kube:            k8s.kube,
-		hostKube:        k8s.hostKube,
-		hostAwareConfig: hostAwareConfig,
-		executorInfo:    ei,
-		jobCompleter: &packageInstallJobCompleter{
-			client:     k8s.kube,
-			hostClient: k8s.hostKube,
-			podLogReader: &K8sReader{
-				Client: k8s.hostClient,
-			},
-			log: log,
+	jc := &packageInstallJobCompleter{
+		client:     k8s.kube,
+		hostClient: k8s.hostKube,
+		podLogReader: &K8sReader{
+			Client: k8s.hostClient,
 		},
+		log:                    log,
+		record:                 event.NewNopRecorder(),
+		forceImagePullPolicy:   forceImagePullPolicy,
+		defaultImagePullPolicy: f.defaultImagePullPolicy,
+	}
+	for _, o := range f.jobCompleterOptions {
+		o(jc)
+	}
+
+	return &packageInstallHandler{
+		ext:                      ext,
+		kube:                     k8s.kube,
+		hostKube:                 k8s.hostKube,
+		hostAwareConfig:          hostAwareConfig,
+		executorInfo:             ei,
+		jobCompleter:             jc,
 		log:                      log,
 		templatesControllerImage: templatesControllerImage,
 		forceImagePullPolicy:     forceImagePullPolicy,
+		defaultImagePullPolicy:   f.defaultImagePullPolicy,
+		unpackResources:          f.unpackResources,
+		establishCooldown:        jc.cooldown,
 	}
 }
 
@@ -261,6 +317,25 @@ func (f *handlerFactory) newHandler(log logging.Logger, ext v1alpha1.PackageInst
 // Syncing/Creating functions
 // ************************************************************************************************
 func (h *packageInstallHandler) sync(ctx context.Context) (reconcile.Result, error) {
+	if _, ok := h.ext.GetAnnotations()[packages.AnnotationForceReestablish]; ok {
+		v, handle := packages.ForceReestablish(h.ext)
+		if !handle {
+			return h.forceReestablished(ctx, v)
+		}
+
+		job, err := h.completedInstallJob(ctx)
+		if err != nil {
+			return fail(ctx, h.kube, h.ext, err)
+		}
+
+		// If the install job has not yet completed its objects will be
+		// established as usual once it has, so we carry on and handle the
+		// annotation once there is something to reestablish.
+		if job != nil {
+			return h.reestablish(ctx, job, v)
+		}
+	}
+
 	sr := h.ext.PackageRecord()
 	if sr == nil || sr.UID == "" {
 		// If we observe the Package, InstallJob succeeded and we're done.
@@ -289,6 +364,60 @@ func (h *packageInstallHandler) sync(ctx context.Context) (reconcile.Result, err
 	return h.update(ctx)
 }
 
+// completedInstallJob returns the install job if it exists and has completed,
+// or nil otherwise.
+func (h *packageInstallHandler) completedInstallJob(ctx context.Context) (*batchv1.Job, error) {
+	jobRef := h.ext.InstallJob()
+	if jobRef == nil {
+		return nil, nil
+	}
+
+	job := &batchv1.Job{}
+	if err := h.hostKube.Get(ctx, meta.NamespacedNameOf(jobRef), job); err != nil {
+		return nil, runtimeresource.IgnoreNotFound(err)
+	}
+	if !jobCompleted(job) {
+		return nil, nil
+	}
+	return job, nil
+}
+
+// reestablish the objects output by the supplied completed install job when
+// asked to by the supplied value of the force-reestablish annotation, then
+// record that the value was handled. The objects are reestablished only once
+// the pre-activation hook, if any, has succeeded, as when they were first
+// established.
+func (h *packageInstallHandler) reestablish(ctx context.Context, job *batchv1.Job, v string) (reconcile.Result, error) {
+	h.debugWithName("reestablishing install job output", "job", job.Name, "value", v)
+	if activated, result, err := h.activate(ctx, job); !activated {
+		return result, err
+	}
+	h.ext.SetConditions(runtimev1alpha1.ReconcileSuccess())
+	if err := h.kube.Status().Update(ctx, h.ext); err != nil {
+		return resultRequeue, err
+	}
+	return h.forceReestablished(ctx, v)
+}
+
+// forceReestablished records that the supplied value of the force-reestablish
+// annotation was handled, and removes the annotation. Recording the value
+// ensures that an annotation that is set again to the same value doesn't cause
+// us to reestablish again.
+func (h *packageInstallHandler) forceReestablished(ctx context.Context, v string) (reconcile.Result, error) {
+	packages.ForceReestablished(h.ext, v)
+	return requeueOnSuccess, h.kube.Update(ctx, h.ext)
+}
+
+// jobCompleted returns true if the supplied job has completed successfully.
+func jobCompleted(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 // create resources (Job, StackDefinition) that yield an associated Package
 // An installjob will be created to unpack the package image. A Package or
 // StackDefinition and CRDs should then be output. The output will be awaited
@@ -433,17 +562,16 @@ func (h *packageInstallHandler) prepareInstallJob(name, namespace string, labels
 	executorInfo := h.executorInfo
 	tscImage := h.templatesControllerImage
 
+	// A package tarball is fetched from its URL, which has no source.
 	pkg := i.GetPackage()
-	img, err := i.ImageWithSource(pkg)
-	if err != nil {
-		// Applying the source is best-effort
-		h.log.Debug("not applying packageinstall source to installjob image due to error", "pkg", pkg, "err", err)
-		img = pkg
-	}
-
-	imagePullPolicy := corev1.PullPolicy(h.forceImagePullPolicy)
-	if imagePullPolicy == "" {
-		imagePullPolicy = i.GetImagePullPolicy()
+	img := pkg
+	if !packages.IsTarballURL(pkg) {
+		var err error
+		if img, err = i.ImageWithSource(pkg); err != nil {
+			// Applying the source is best-effort
+			h.log.Debug("not applying packageinstall source to installjob image due to error", "pkg", pkg, "err", err)
+			img = pkg
+		}
 	}
 
 	return buildInstallJob(buildInstallJobParams{
@@ -454,10 +582,12 @@ func (h *packageInstallHandler) prepareInstallJob(name, namespace string, labels
 		packageManagerImage:      executorInfo.Image,
 		tscImage:                 tscImage,
 		packageManagerPullPolicy: executorInfo.ImagePullPolicy,
-		imagePullPolicy:          imagePullPolicy,
+		imagePullPolicy:          imagePullPolicy(i, h.forceImagePullPolicy, h.defaultImagePullPolicy),
 		labels:                   labels,
 		annotations:              annotations,
-		imagePullSecrets:         imagePullSecrets})
+		imagePullSecrets:         imagePullSecrets,
+		packageSHA256:            i.GetAnnotations()[packages.AnnotationPackageSHA256],
+		resources:                h.unpackResources})
 }
 
 func (h *packageInstallHandler) awaitInstallJob(ctx context.Context, jobRef *corev1.ObjectReference) (reconcile.Result, error) {
@@ -477,9 +607,9 @@ func (h *packageInstallHandler) awaitInstallJob(ctx context.Context, jobRef *cor
 		if c.Status == corev1.ConditionTrue {
 			switch c.Type {
 			case batchv1.JobComplete:
-				// the installjob succeeded, process the output
-				if err := h.jobCompleter.handleJobCompletion(ctx, h.ext, job); err != nil {
-					return fail(ctx, h.kube, h.ext, err)
+				// the installjob succeeded, so activate the package
+				if activated, result, err := h.activate(ctx, job); !activated {
+					return result, err
 				}
 
 				// the installjob output was handled successfully
@@ -499,6 +629,52 @@ func (h *packageInstallHandler) awaitInstallJob(ctx context.Context, jobRef *cor
 	return requeueOnSuccess, h.kube.Status().Update(ctx, h.ext)
 }
 
+// activate establishes the objects output by the supplied completed install
+// job, once the pre-activation hook, if any, has succeeded. It returns true if
+// the objects were established. Otherwise the returned result and error should
+// be returned from the reconcile.
+func (h *packageInstallHandler) activate(ctx context.Context, job *batchv1.Job) (bool, reconcile.Result, error) {
+	// the package must not be activated until its pre-activation hook
+	// succeeds, if any
+	if hook := h.ext.GetPreActivationHook(); hook != nil {
+		done, err := h.awaitPreActivationHook(ctx, hook, job)
+		if err != nil {
+			result, err := fail(ctx, h.kube, h.ext, err)
+			return false, result, err
+		}
+		if !done {
+			h.ext.SetConditions(runtimev1alpha1.ReconcileSuccess())
+			h.log.Debug("pre-activation hook job not complete", "job", fmt.Sprintf("%s/%s", job.Namespace, job.Name))
+			return false, requeueOnSuccess, h.kube.Status().Update(ctx, h.ext)
+		}
+	}
+
+	// don't try to establish the output again too soon after a transient
+	// failure
+	if wait := h.establishCooldownRemaining(); wait > 0 {
+		h.log.Debug("waiting to process install job output after a transient failure", "job", fmt.Sprintf("%s/%s", job.Namespace, job.Name), "wait", wait)
+		return false, reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	// process the output
+	if err := h.jobCompleter.handleJobCompletion(ctx, h.ext, job); err != nil {
+		result, err := h.failEstablish(ctx, err)
+		return false, result, err
+	}
+	h.ext.SetNextEstablishAttempt(nil)
+
+	// the hook job is only deleted once the package is activated, so that it
+	// never runs more than once per activation
+	if h.ext.GetPreActivationHook() != nil {
+		if err := h.deletePreActivationHook(ctx, job); err != nil {
+			result, err := fail(ctx, h.kube, h.ext, err)
+			return false, result, err
+		}
+	}
+
+	return true, reconcile.Result{}, nil
+}
+
 func (h *packageInstallHandler) update(ctx context.Context) (reconcile.Result, error) {
 	h.debugWithName("updating not supported yet")
 	return reconcile.Result{}, nil
@@ -674,3 +850,37 @@ func fail(ctx context.Context, kube client.StatusClient, i v1alpha1.PackageInsta
 	i.SetConditions(runtimev1alpha1.ReconcileError(err))
 	return resultRequeue, kube.Status().Update(ctx, i)
 }
+
+// establishCooldownRemaining returns how long to wait before trying again to
+// establish the output of the install job, or zero if we need not wait.
+func (h *packageInstallHandler) establishCooldownRemaining() time.Duration {
+	next := h.ext.GetNextEstablishAttempt()
+	if next == nil {
+		return 0
+	}
+	return time.Until(next.Time)
+}
+
+// failEstablish is like failJobCompletion, but if a cooldown is configured it
+// records when establishment should next be attempted after a retryable error,
+// and waits until then before requeueing.
+func (h *packageInstallHandler) failEstablish(ctx context.Context, err error) (reconcile.Result, error) {
+	if h.establishCooldown == 0 || !retryable(err) {
+		return failJobCompletion(ctx, h.kube, h.ext, err)
+	}
+	next := metav1.NewTime(time.Now().Add(h.establishCooldown))
+	h.ext.SetNextEstablishAttempt(&next)
+	h.ext.SetConditions(runtimev1alpha1.ReconcileError(err))
+	return reconcile.Result{RequeueAfter: h.establishCooldown}, h.kube.Status().Update(ctx, h.ext)
+}
+
+// failJobCompletion is like fail, but waits longer before requeueing if the
+// supplied error is one that retrying is unlikely to resolve, to avoid
+// hammering the API server.
+func failJobCompletion(ctx context.Context, kube client.StatusClient, i v1alpha1.PackageInstaller, err error) (reconcile.Result, error) {
+	if retryable(err) {
+		return fail(ctx, kube, i, err)
+	}
+	i.SetConditions(runtimev1alpha1.ReconcileError(err))
+	return requeuePermanent, kube.Status().Update(ctx, i)
+}
@@ -17,13 +17,17 @@ limitations under the License.
 package install
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 
@@ -41,6 +45,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -82,6 +87,10 @@ func withFinalizers(finalizers ...string) resourceModifier {
 	return func(r v1alpha1.PackageInstaller) { r.SetFinalizers(finalizers) }
 }
 
+func withAnnotations(a map[string]string) resourceModifier {
+	return func(r v1alpha1.PackageInstaller) { meta.AddAnnotations(r, a) }
+}
+
 func withResourceVersion(version string) resourceModifier {
 	return func(r v1alpha1.PackageInstaller) { r.SetResourceVersion(version) }
 }
@@ -100,6 +109,14 @@ func withInstallJob(jobRef *corev1.ObjectReference) resourceModifier {
 	return func(r v1alpha1.PackageInstaller) { r.SetInstallJob(jobRef) }
 }
 
+func withObjectCounts(established, total int) resourceModifier {
+	return func(r v1alpha1.PackageInstaller) { r.SetObjectCounts(established, total) }
+}
+
+func withAdoptedCount(adopted int) resourceModifier {
+	return func(r v1alpha1.PackageInstaller) { r.SetAdoptedCount(adopted) }
+}
+
 // TODO(displague) this should be used in a test that asserts packageinstalls
 // get status.packages when the package already exists and is properly labeled
 //nolint:deadcode,unused
@@ -120,6 +137,16 @@ func withPackage(pkg string) resourceModifier {
 	}
 }
 
+func withPreActivationHook(hook *v1alpha1.PreActivationHook) resourceModifier {
+	return func(r v1alpha1.PackageInstaller) {
+		if si, ok := r.(*v1alpha1.PackageInstall); ok {
+			si.Spec.PreActivationHook = hook
+		} else if csi, ok := r.(*v1alpha1.ClusterPackageInstall); ok {
+			csi.Spec.PreActivationHook = hook
+		}
+	}
+}
+
 func withSource(src string) resourceModifier {
 	return func(r v1alpha1.PackageInstaller) { r.SetSource(src) }
 }
@@ -354,6 +381,130 @@ func TestReconcile(t *testing.T) {
 			},
 			want: want{result: reconcile.Result{}, err: nil},
 		},
+		{
+			name: "PausedPackageInstall",
+			req:  reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: namespace}},
+			rec: &Reconciler{
+				k8sClients: k8sClients{
+					kube: &test.MockClient{
+						MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+							*obj.(*v1alpha1.PackageInstall) = *(packageInstallResource(withAnnotations(map[string]string{packages.AnnotationPaused: "true"})))
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+						MockStatusUpdate: func(ctx context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+							want := packageInstallResource(
+								withAnnotations(map[string]string{packages.AnnotationPaused: "true"}),
+								withConditions(v1alpha1.ReconcilePaused()),
+								withFinalizers(installFinalizer),
+							)
+							if diff := cmp.Diff(want, obj, test.EquateConditions()); diff != "" {
+								t.Errorf("StatusUpdate(...): -want, +got:\n%s", diff)
+							}
+							return nil
+						},
+					},
+				},
+				packinator: func() v1alpha1.PackageInstaller { return &v1alpha1.PackageInstall{} },
+				executorInfoDiscoverer: &mockExecutorInfoDiscoverer{
+					MockDiscoverExecutorInfo: func(ctx context.Context) (*packages.ExecutorInfo, error) {
+						return &packages.ExecutorInfo{Image: packagePackageImage}, nil
+					},
+				},
+				factory: &mockFactory{
+					MockNewHandler: func(logging.Logger, v1alpha1.PackageInstaller, k8sClients, *hosted.Config, *packages.ExecutorInfo, string, string) handler {
+						return &mockHandler{
+							MockSync: func(context.Context) (reconcile.Result, error) {
+								t.Errorf("unexpected sync while paused")
+								return reconcile.Result{}, errBoom
+							},
+						}
+					},
+				},
+				log: logging.NewNopLogger(),
+			},
+			want: want{result: reconcile.Result{}, err: nil},
+		},
+		{
+			name: "AlreadyPausedPackageInstall",
+			req:  reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: namespace}},
+			rec: &Reconciler{
+				k8sClients: k8sClients{
+					kube: &test.MockClient{
+						MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+							*obj.(*v1alpha1.PackageInstall) = *(packageInstallResource(
+								withAnnotations(map[string]string{packages.AnnotationPaused: "true"}),
+								withConditions(v1alpha1.ReconcilePaused()),
+								withFinalizers(installFinalizer),
+							))
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+						MockStatusUpdate: func(ctx context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+							t.Errorf("unexpected status update of %T that already reports it is paused", obj)
+							return errBoom
+						},
+					},
+				},
+				packinator: func() v1alpha1.PackageInstaller { return &v1alpha1.PackageInstall{} },
+				executorInfoDiscoverer: &mockExecutorInfoDiscoverer{
+					MockDiscoverExecutorInfo: func(ctx context.Context) (*packages.ExecutorInfo, error) {
+						return &packages.ExecutorInfo{Image: packagePackageImage}, nil
+					},
+				},
+				factory: &mockFactory{
+					MockNewHandler: func(logging.Logger, v1alpha1.PackageInstaller, k8sClients, *hosted.Config, *packages.ExecutorInfo, string, string) handler {
+						return &mockHandler{
+							MockSync: func(context.Context) (reconcile.Result, error) {
+								t.Errorf("unexpected sync while paused")
+								return reconcile.Result{}, errBoom
+							},
+						}
+					},
+				},
+				log: logging.NewNopLogger(),
+			},
+			want: want{result: reconcile.Result{}, err: nil},
+		},
+		{
+			name: "DeletedPausedPackageInstall",
+			req:  reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: namespace}},
+			rec: &Reconciler{
+				k8sClients: k8sClients{
+					kube: &test.MockClient{
+						MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+							*obj.(*v1alpha1.PackageInstall) = *(packageInstallResource(
+								withAnnotations(map[string]string{packages.AnnotationPaused: "true"}),
+								withDeletionTimestamp(time.Now()),
+							))
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+						MockStatusUpdate: func(ctx context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+							t.Errorf("unexpected status update of deleted %T", obj)
+							return errBoom
+						},
+					},
+				},
+				packinator: func() v1alpha1.PackageInstaller { return &v1alpha1.PackageInstall{} },
+				executorInfoDiscoverer: &mockExecutorInfoDiscoverer{
+					MockDiscoverExecutorInfo: func(ctx context.Context) (*packages.ExecutorInfo, error) {
+						return &packages.ExecutorInfo{Image: packagePackageImage}, nil
+					},
+				},
+				factory: &mockFactory{
+					MockNewHandler: func(logging.Logger, v1alpha1.PackageInstaller, k8sClients, *hosted.Config, *packages.ExecutorInfo, string, string) handler {
+						return &mockHandler{
+							MockDelete: func(context.Context) (reconcile.Result, error) {
+								return reconcile.Result{}, nil
+							},
+						}
+					},
+				},
+				log: logging.NewNopLogger(),
+			},
+			want: want{result: reconcile.Result{}, err: nil},
+		},
 		{
 			name: "PackageGetFailed",
 			req:  reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: namespace}},
@@ -768,6 +919,266 @@ func TestPackageInstallDelete(t *testing.T) {
 	}
 }
 
+func TestReestablish(t *testing.T) {
+	type want struct {
+		result reconcile.Result
+		err    error
+		ext    *v1alpha1.PackageInstall
+	}
+
+	jobRef := &corev1.ObjectReference{Name: resourceName, Namespace: namespace}
+	completedJob := func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+		*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+		return nil
+	}
+	unreachable := &mockJobCompleter{
+		MockHandleJobCompletion: func(_ context.Context, _ v1alpha1.PackageInstaller, _ *batchv1.Job) error {
+			return errors.New("job output should not be reestablished")
+		},
+	}
+
+	tests := []struct {
+		name    string
+		handler *packageInstallHandler
+		want    want
+	}{
+		{
+			name: "ReestablishCompletedInstallJob",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockUpdate:       test.NewMockUpdateFn(nil),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				hostKube: &test.MockClient{MockGet: completedJob},
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(_ context.Context, i v1alpha1.PackageInstaller, _ *batchv1.Job) error {
+						i.SetConditions(v1alpha1.Established(1))
+						return nil
+					},
+				},
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+				),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablished: "2020-04-01T00:00:00Z"}),
+					withConditions(v1alpha1.Established(1), runtimev1alpha1.ReconcileSuccess()),
+				),
+			},
+		},
+		{
+			name: "PreActivationHookNotCompleted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+						return errors.New("the annotation should not be handled before the hook completes")
+					},
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+						// The hook job exists, but has not yet completed.
+						if key.Name == resourceName+preActivationHookSuffix {
+							*obj.(*batchv1.Job) = *(preActivationHookJob(&v1alpha1.PreActivationHook{}, job()))
+							return nil
+						}
+						*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+						return nil
+					},
+				},
+				jobCompleter: unreachable,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+				),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withPreActivationHook(&v1alpha1.PreActivationHook{}),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+					withConditions(runtimev1alpha1.ReconcileSuccess()),
+				),
+			},
+		},
+		{
+			name: "AlreadyReestablished",
+			handler: &packageInstallHandler{
+				kube:         &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				hostKube:     &test.MockClient{MockGet: completedJob},
+				jobCompleter: unreachable,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{
+						packages.AnnotationForceReestablish:   "2020-04-01T00:00:00Z",
+						packages.AnnotationForceReestablished: "2020-04-01T00:00:00Z",
+					}),
+				),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: requeueOnSuccess,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablished: "2020-04-01T00:00:00Z"}),
+				),
+			},
+		},
+		{
+			name: "InstallJobNotCompleted",
+			handler: &packageInstallHandler{
+				kube: &test.MockClient{
+					MockGet:          test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, resourceName)),
+					MockPatch:        test.NewMockPatchFn(nil),
+					MockStatusPatch:  test.NewMockStatusPatchFn(nil),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				hostKube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+						*obj.(*batchv1.Job) = *(job())
+						return nil
+					},
+				},
+				jobCompleter: unreachable,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+				),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				// The annotation is kept until there is something to
+				// reestablish, while the install proceeds as usual.
+				result: requeueOnSuccess,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+					withFinalizers(installFinalizer),
+					withConditions(runtimev1alpha1.Creating(), runtimev1alpha1.ReconcileSuccess()),
+				),
+			},
+		},
+		{
+			name: "ReestablishFailed",
+			handler: &packageInstallHandler{
+				kube:     &test.MockClient{MockStatusUpdate: test.NewMockStatusUpdateFn(nil)},
+				hostKube: &test.MockClient{MockGet: completedJob},
+				jobCompleter: &mockJobCompleter{
+					MockHandleJobCompletion: func(_ context.Context, _ v1alpha1.PackageInstaller, _ *batchv1.Job) error { return errBoom },
+				},
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+				),
+				log: logging.NewNopLogger(),
+			},
+			want: want{
+				result: resultRequeue,
+				ext: packageInstallResource(
+					withInstallJob(jobRef),
+					withAnnotations(map[string]string{packages.AnnotationForceReestablish: "2020-04-01T00:00:00Z"}),
+					withConditions(runtimev1alpha1.ReconcileError(errBoom)),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, gotErr := tt.handler.sync(ctx)
+
+			if diff := cmp.Diff(tt.want.err, gotErr, test.EquateErrors()); diff != "" {
+				t.Errorf("sync() -want error, +got error:\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.result, gotResult); diff != "" {
+				t.Errorf("sync() -want, +got:\n%v", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.ext, tt.handler.ext, test.EquateConditions()); diff != "" {
+				t.Errorf("sync() -want, +got:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestReestablishExistingObjects(t *testing.T) {
+	const annotation = "2020-04-01T00:00:00Z"
+	jobRef := &corev1.ObjectReference{Name: resourceName, Namespace: namespace}
+
+	ext := packageInstallResource(withInstallJob(jobRef))
+	fc := fake.NewFakeClient(ext)
+	kube := &test.MockClient{
+		MockCreate:       fc.Create,
+		MockGet:          fc.Get,
+		MockPatch:        fc.Patch,
+		MockUpdate:       fc.Update,
+		MockStatusUpdate: fc.Status().Update,
+		MockList:         test.NewMockListFn(nil),
+	}
+	jc := &packageInstallJobCompleter{
+		client: kube,
+		hostClient: &test.MockClient{
+			MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+				*list.(*corev1.PodList) = corev1.PodList{Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: jobPodName}}}}
+				return nil
+			},
+		},
+		podLogReader: &mockPodLogReader{
+			MockGetPodLogReader: func(string, string) (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader([]byte(managedCRDRaw + packageRaw("crossplane/sample-package:latest")))), nil
+			},
+		},
+		log:    logging.NewNopLogger(),
+		record: event.NewNopRecorder(),
+	}
+
+	// The job output was established when the install job completed.
+	if err := jc.handleJobCompletion(ctx, ext, job()); err != nil {
+		t.Fatalf("handleJobCompletion(...): %s", err)
+	}
+
+	// Reestablishing it should update the objects that already exist and
+	// consider all of them established.
+	meta.AddAnnotations(ext, map[string]string{packages.AnnotationForceReestablish: annotation})
+	h := &packageInstallHandler{
+		kube: kube,
+		hostKube: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			*obj.(*batchv1.Job) = *(job(withJobConditions(batchv1.JobComplete, "")))
+			return nil
+		}},
+		jobCompleter: jc,
+		ext:          ext,
+		log:          logging.NewNopLogger(),
+	}
+
+	got, err := h.sync(ctx)
+	if err != nil {
+		t.Fatalf("sync(...): %s", err)
+	}
+	if diff := cmp.Diff(requeueOnSuccess, got); diff != "" {
+		t.Errorf("sync(...): -want, +got:\n%s", diff)
+	}
+
+	want := packageInstallResource(
+		withInstallJob(jobRef),
+		withAnnotations(map[string]string{packages.AnnotationForceReestablished: annotation}),
+		withObjectCounts(2, 2),
+		withConditions(v1alpha1.Established(2), runtimev1alpha1.ReconcileSuccess()),
+	)
+	if diff := cmp.Diff(want, h.ext, test.EquateConditions(), cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion")); diff != "" {
+		t.Errorf("sync(...): -want, +got:\n%s", diff)
+	}
+}
+
 func TestHandlerFactory(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -783,6 +1194,24 @@ func TestHandlerFactory(t *testing.T) {
 					client:       nil,
 					podLogReader: &K8sReader{Client: nil},
 					log:          logging.NewNopLogger(),
+					record:       event.NewNopRecorder(),
+				},
+				executorInfo:             &packages.ExecutorInfo{Image: packagePackageImage},
+				ext:                      packageInstallResource(),
+				log:                      logging.NewNopLogger(),
+				templatesControllerImage: tsControllerImage,
+			},
+		},
+		{
+			name:    "WithEstablishClient",
+			factory: &handlerFactory{jobCompleterOptions: []JobCompleterOption{WithEstablishClient(&test.MockClient{})}},
+			want: &packageInstallHandler{
+				kube: nil,
+				jobCompleter: &packageInstallJobCompleter{
+					client:       &test.MockClient{},
+					podLogReader: &K8sReader{Client: nil},
+					log:          logging.NewNopLogger(),
+					record:       event.NewNopRecorder(),
 				},
 				executorInfo:             &packages.ExecutorInfo{Image: packagePackageImage},
 				ext:                      packageInstallResource(),
@@ -818,6 +1247,14 @@ func withCRDVersion(version string) crdModifier {
 	}
 }
 
+func withCRDStorageVersion(version string) crdModifier {
+	return func(c *apiextensions.CustomResourceDefinition) {
+		for i := range c.Spec.Versions {
+			c.Spec.Versions[i].Storage = c.Spec.Versions[i].Name == version
+		}
+	}
+}
+
 func withCRDLabels(labels map[string]string) crdModifier {
 	return func(c *apiextensions.CustomResourceDefinition) {
 		meta.AddLabels(c, labels)
@@ -839,12 +1276,40 @@ func withCRDGroupKind(group, kind string) crdModifier {
 	}
 }
 
+func withCRDPreserveUnknownFields(p bool) crdModifier {
+	return func(c *apiextensions.CustomResourceDefinition) {
+		c.Spec.PreserveUnknownFields = &p
+	}
+}
+
+func withCRDExternalConversionWebhook(url string, caBundle []byte) crdModifier {
+	return func(c *apiextensions.CustomResourceDefinition) {
+		c.Spec.Conversion = &apiextensions.CustomResourceConversion{
+			Strategy: apiextensions.WebhookConverter,
+			WebhookClientConfig: &apiextensions.WebhookClientConfig{
+				URL:      &url,
+				CABundle: caBundle,
+			},
+		}
+	}
+}
+
 func withCRDDeletionTimestamp(t time.Time) crdModifier {
 	return func(r *apiextensions.CustomResourceDefinition) {
 		r.SetDeletionTimestamp(&metav1.Time{Time: t})
 	}
 }
 
+func withCRDCondition(ct apiextensions.CustomResourceDefinitionConditionType, msg string) crdModifier {
+	return func(r *apiextensions.CustomResourceDefinition) {
+		r.Status.Conditions = append(r.Status.Conditions, apiextensions.CustomResourceDefinitionCondition{
+			Type:    ct,
+			Status:  apiextensions.ConditionTrue,
+			Message: msg,
+		})
+	}
+}
+
 func crd(cm ...crdModifier) apiextensions.CustomResourceDefinition {
 	// basic crd with defaults
 	t := true
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// Span names.
+const (
+	spanEstablish       = "Establish"
+	spanEstablishObject = "EstablishObject"
+)
+
+// Span attribute keys.
+const (
+	attrRevision = "revision"
+	attrJob      = "job"
+	attrGVK      = "gvk"
+	attrName     = "name"
+	attrAction   = "action"
+)
+
+// An Attribute describes a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// A Tracer starts spans. Implementations are expected to start each span as a
+// child of any span in the supplied context, and to return a context that
+// contains the new span. A Tracer may be implemented using OpenTelemetry, or
+// any other tracing library.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// A Span represents an operation that is being traced.
+type Span interface {
+	// SetAttributes adds the supplied attributes to the span.
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records that the operation failed with the supplied
+	// error.
+	RecordError(err error)
+
+	// End the span.
+	End()
+}
+
+// A TracerFn is a function that satisfies the Tracer interface.
+type TracerFn func(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+
+// Start a span.
+func (fn TracerFn) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return fn(ctx, name, attrs...)
+}
+
+// A NopTracer starts spans that do nothing.
+type NopTracer struct{}
+
+// Start a span that does nothing.
+func (NopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(_ ...Attribute) {}
+func (nopSpan) RecordError(_ error)          {}
+func (nopSpan) End()                         {}
+
+// A LogTracer starts spans that are logged at debug level when they end, along
+// with their attributes, duration, and any error. It can be used to find slow
+// establishments without a tracing backend.
+type LogTracer struct {
+	log logging.Logger
+}
+
+// NewLogTracer returns a Tracer that logs spans using the supplied logger.
+func NewLogTracer(l logging.Logger) *LogTracer {
+	return &LogTracer{log: l}
+}
+
+// Start a span that is logged when it ends.
+func (t *LogTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, &logSpan{log: t.log, name: name, attrs: attrs, start: time.Now()}
+}
+
+type logSpan struct {
+	log   logging.Logger
+	name  string
+	attrs []Attribute
+	start time.Time
+	err   error
+}
+
+func (s *logSpan) SetAttributes(attrs ...Attribute) { s.attrs = append(s.attrs, attrs...) }
+func (s *logSpan) RecordError(err error)            { s.err = err }
+
+func (s *logSpan) End() {
+	kv := []interface{}{"span", s.name, "duration", time.Since(s.start).String()}
+	for _, a := range s.attrs {
+		kv = append(kv, a.Key, a.Value)
+	}
+	if s.err != nil {
+		kv = append(kv, "error", s.err)
+	}
+	s.log.Debug("Traced package establishment", kv...)
+}
+
+// WithTracer specifies the Tracer used to trace the establishment of the
+// objects output by a package install job. A span is started around the
+// establishment of all of a job's objects, and around that of each object.
+// Tracing is a no-op without this option.
+func WithTracer(t Tracer) JobCompleterOption {
+	return func(jc *packageInstallJobCompleter) {
+		jc.tracer = t
+	}
+}
+
+// startSpan starts a span using the completer's Tracer, if any.
+func (jc *packageInstallJobCompleter) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if jc.tracer == nil {
+		return NopTracer{}.Start(ctx, name, attrs...)
+	}
+	return jc.tracer.Start(ctx, name, attrs...)
+}
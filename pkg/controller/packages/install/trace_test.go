@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	Name   string
+	Parent string
+	Attrs  []Attribute
+	Err    string
+	Ended  bool
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{Name: name, Attrs: attrs}
+	if p, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.Parent = p.Name
+	}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) { s.Attrs = append(s.Attrs, attrs...) }
+func (s *recordedSpan) RecordError(err error)            { s.Err = err.Error() }
+func (s *recordedSpan) End()                             { s.Ended = true }
+
+func TestEstablishTracing(t *testing.T) {
+	obj := unstructuredObj(crdRaw)
+	gvk := obj.GroupVersionKind().String()
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		jc     *packageInstallJobCompleter
+		want   []*recordedSpan
+	}{
+		"Established": {
+			reason: "A span should be started around establishment, and a child span around each object",
+			jc:     &packageInstallJobCompleter{client: fake.NewFakeClient(), log: logging.NewNopLogger()},
+			want: []*recordedSpan{
+				{
+					Name:  spanEstablish,
+					Attrs: []Attribute{{Key: attrRevision, Value: namespace + "/" + resourceName}, {Key: attrJob, Value: namespace + "/" + resourceName}},
+					Ended: true,
+				},
+				{
+					Name:   spanEstablishObject,
+					Parent: spanEstablish,
+					Attrs:  []Attribute{{Key: attrGVK, Value: gvk}, {Key: attrName, Value: crdName}, {Key: attrAction, Value: "create"}},
+					Ended:  true,
+				},
+			},
+		},
+		"Error": {
+			reason: "Spans should record errors",
+			jc:     &packageInstallJobCompleter{client: &test.MockClient{MockCreate: test.NewMockCreateFn(errBoom)}, log: logging.NewNopLogger()},
+			want: []*recordedSpan{
+				{
+					Name:  spanEstablish,
+					Attrs: []Attribute{{Key: attrRevision, Value: namespace + "/" + resourceName}, {Key: attrJob, Value: namespace + "/" + resourceName}},
+					Err:   errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, resourceName).Error(),
+					Ended: true,
+				},
+				{
+					Name:   spanEstablishObject,
+					Parent: spanEstablish,
+					Attrs:  []Attribute{{Key: attrGVK, Value: gvk}, {Key: attrName, Value: crdName}, {Key: attrAction, Value: "skip"}},
+					Err:    errors.Wrapf(errBoom, "failed to create object %s from job output %s", crdName, resourceName).Error(),
+					Ended:  true,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tr := &recordingTracer{}
+			WithTracer(tr)(tc.jc)
+
+			_, _, _ = tc.jc.establishAll(context.Background(), []*unstructured.Unstructured{obj.DeepCopy()}, packageInstallResource(), job())
+			if diff := cmp.Diff(tc.want, tr.spans); diff != "" {
+				t.Errorf("\n%s\nestablishAll(...): -want spans, +got spans:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+const (
+	errMissingPackage       = "package does not declare a Package or StackDefinition"
+	errMultiplePackages     = "package declares more than one Package or StackDefinition"
+	errMissingTitle         = "package metadata is missing a title"
+	errFmtDuplicateCRD      = "package declares CRD %s more than once"
+	errFmtDuplicateWebhook  = "package declares %s %s more than once"
+	errFmtConflictingHook   = "package declares webhook %s more than once in %s %s"
+	errFmtConvertPackageObj = "cannot read metadata of Package %s"
+)
+
+var (
+	crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+	webhookConfigurationGroupKinds = map[schema.GroupKind]bool{
+		{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	}
+)
+
+// PackageMetadata returns the metadata of the Package or StackDefinition
+// declared by the supplied objects output by a package install job. It returns
+// an error unless exactly one is declared.
+func PackageMetadata(objs []*unstructured.Unstructured) (*v1alpha1.AppMetadataSpec, error) {
+	var pkg *unstructured.Unstructured
+	for _, o := range objs {
+		if !isPackageObject(o) && !isStackDefinitionObject(o) {
+			continue
+		}
+		if pkg != nil {
+			return nil, errors.New(errMultiplePackages)
+		}
+		pkg = o
+	}
+	if pkg == nil {
+		return nil, errors.New(errMissingPackage)
+	}
+
+	// Both kinds inline their app metadata in their spec.
+	spec, _, _ := unstructured.NestedMap(pkg.Object, "spec")
+	meta := &v1alpha1.AppMetadataSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, meta); err != nil {
+		return nil, errors.Wrapf(err, errFmtConvertPackageObj, pkg.GetName())
+	}
+	return meta, nil
+}
+
+// ValidatePackage checks the supplied objects output by a package install job,
+// and the supplied metadata of the package that output them, for common
+// problems. It returns every problem found, or nil if none were.
+func ValidatePackage(objs []*unstructured.Unstructured, meta *v1alpha1.AppMetadataSpec) []error {
+	var errs []error
+
+	if meta != nil && meta.Title == "" {
+		errs = append(errs, errors.New(errMissingTitle))
+	}
+
+	seen := map[schema.GroupKind]map[string]bool{}
+	for _, o := range objs {
+		gk := o.GroupVersionKind().GroupKind()
+		if gk != crdGroupKind && !webhookConfigurationGroupKinds[gk] {
+			continue
+		}
+
+		if seen[gk] == nil {
+			seen[gk] = map[string]bool{}
+		}
+		if seen[gk][o.GetName()] {
+			if gk == crdGroupKind {
+				errs = append(errs, errors.Errorf(errFmtDuplicateCRD, o.GetName()))
+				continue
+			}
+			errs = append(errs, errors.Errorf(errFmtDuplicateWebhook, gk.Kind, o.GetName()))
+			continue
+		}
+		seen[gk][o.GetName()] = true
+
+		if webhookConfigurationGroupKinds[gk] {
+			errs = append(errs, conflictingWebhooks(o)...)
+		}
+	}
+
+	return errs
+}
+
+// conflictingWebhooks returns an error for each webhook name that appears more
+// than once in the supplied webhook configuration.
+func conflictingWebhooks(o *unstructured.Unstructured) []error {
+	hooks, _, _ := unstructured.NestedSlice(o.Object, "webhooks")
+
+	var errs []error
+	names := map[string]bool{}
+	for _, h := range hooks {
+		m, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		n, _ := m["name"].(string)
+		if names[n] {
+			errs = append(errs, errors.Errorf(errFmtConflictingHook, n, o.GetKind(), o.GetName()))
+			continue
+		}
+		names[n] = true
+	}
+	return errs
+}
+
+// joinErrors returns a single error whose message is those of the supplied
+// errors, separated by semicolons.
+func joinErrors(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
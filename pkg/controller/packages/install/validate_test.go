@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
+)
+
+func TestPackageMetadata(t *testing.T) {
+	pkg := func(kind, title string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": v1alpha1.SchemeGroupVersion.String(),
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "cool"},
+			"spec":       map[string]interface{}{"title": title, "version": "v0.1.0", "controller": map[string]interface{}{}},
+		}}
+	}
+
+	type want struct {
+		meta *v1alpha1.AppMetadataSpec
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		objs   []*unstructured.Unstructured
+		want   want
+	}{
+		"Package": {
+			reason: "The metadata of the declared Package should be returned",
+			objs:   []*unstructured.Unstructured{pkg(v1alpha1.PackageKind, "Cool")},
+			want:   want{meta: &v1alpha1.AppMetadataSpec{Title: "Cool", Version: "v0.1.0"}},
+		},
+		"StackDefinition": {
+			reason: "The metadata of the declared StackDefinition should be returned",
+			objs:   []*unstructured.Unstructured{pkg(v1alpha1.StackDefinitionKind, "Cool")},
+			want:   want{meta: &v1alpha1.AppMetadataSpec{Title: "Cool", Version: "v0.1.0"}},
+		},
+		"Capabilities": {
+			reason: "The capabilities declared by the Package should be returned",
+			objs: func() []*unstructured.Unstructured {
+				p := pkg(v1alpha1.PackageKind, "Cool")
+				_ = unstructured.SetNestedStringSlice(p.Object, []string{v1alpha1.CapabilityConversionWebhooks}, "spec", "capabilities")
+				return []*unstructured.Unstructured{p}
+			}(),
+			want: want{meta: &v1alpha1.AppMetadataSpec{Title: "Cool", Version: "v0.1.0", Capabilities: []string{v1alpha1.CapabilityConversionWebhooks}}},
+		},
+		"Missing": {
+			reason: "An error should be returned if no Package is declared",
+			want:   want{err: errors.New(errMissingPackage)},
+		},
+		"Multiple": {
+			reason: "An error should be returned if more than one Package is declared",
+			objs:   []*unstructured.Unstructured{pkg(v1alpha1.PackageKind, "Cool"), pkg(v1alpha1.PackageKind, "Cooler")},
+			want:   want{err: errors.New(errMultiplePackages)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			meta, err := PackageMetadata(tc.objs)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPackageMetadata(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.meta, meta); diff != "" {
+				t.Errorf("\n%s\nPackageMetadata(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidatePackage(t *testing.T) {
+	crd := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+	}
+	webhooks := func(kind, name string, hooks ...string) *unstructured.Unstructured {
+		wh := make([]interface{}, len(hooks))
+		for i, h := range hooks {
+			wh[i] = map[string]interface{}{"name": h}
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+			"webhooks":   wh,
+		}}
+	}
+	meta := &v1alpha1.AppMetadataSpec{Title: "Cool"}
+
+	cases := map[string]struct {
+		reason string
+		objs   []*unstructured.Unstructured
+		meta   *v1alpha1.AppMetadataSpec
+		want   []error
+	}{
+		"Valid": {
+			reason: "A package with no problems should be valid",
+			objs: []*unstructured.Unstructured{
+				crd("coolers.example.org"),
+				crd("coolests.example.org"),
+				webhooks("ValidatingWebhookConfiguration", "cool", "a.example.org", "b.example.org"),
+				webhooks("MutatingWebhookConfiguration", "cool", "a.example.org"),
+			},
+			meta: meta,
+		},
+		"MissingTitle": {
+			reason: "A package whose metadata has no title should be invalid",
+			meta:   &v1alpha1.AppMetadataSpec{},
+			want:   []error{errors.New(errMissingTitle)},
+		},
+		"DuplicateCRD": {
+			reason: "A package that declares a CRD more than once should be invalid",
+			objs:   []*unstructured.Unstructured{crd("coolers.example.org"), crd("coolers.example.org")},
+			meta:   meta,
+			want:   []error{errors.Errorf(errFmtDuplicateCRD, "coolers.example.org")},
+		},
+		"DuplicateWebhookConfiguration": {
+			reason: "A package that declares a webhook configuration more than once should be invalid",
+			objs: []*unstructured.Unstructured{
+				webhooks("ValidatingWebhookConfiguration", "cool"),
+				webhooks("ValidatingWebhookConfiguration", "cool"),
+			},
+			meta: meta,
+			want: []error{errors.Errorf(errFmtDuplicateWebhook, "ValidatingWebhookConfiguration", "cool")},
+		},
+		"ConflictingWebhooks": {
+			reason: "A package that declares a webhook configuration with conflicting webhook names should be invalid",
+			objs:   []*unstructured.Unstructured{webhooks("MutatingWebhookConfiguration", "cool", "a.example.org", "a.example.org")},
+			meta:   meta,
+			want:   []error{errors.Errorf(errFmtConflictingHook, "a.example.org", "MutatingWebhookConfiguration", "cool")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ValidatePackage(tc.objs, tc.meta)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidatePackage(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
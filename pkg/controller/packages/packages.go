@@ -17,29 +17,55 @@ limitations under the License.
 package packages
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 
+	"github.com/crossplane/crossplane/apis/packages/v1alpha1"
 	"github.com/crossplane/crossplane/pkg/controller/packages/install"
 	"github.com/crossplane/crossplane/pkg/controller/packages/persona"
 	"github.com/crossplane/crossplane/pkg/controller/packages/pkg"
 )
 
-// Setup Crossplane Packages controllers.
-func Setup(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage string, allowCore, allowFullDeployment bool, forceImagePullPolicy string) error {
-	if err := install.SetupPackageInstall(mgr, l, hostControllerNamespace, tsControllerImage, forceImagePullPolicy); err != nil {
+// Setup Crossplane Packages controllers. The supplied EstablishTracker, if
+// any, is told each time a PackageInstall or ClusterPackageInstall controller
+// establishes the objects output by a package install job, while progress
+// establishing each object is logged at debug level. The supplied
+// default resource requirements apply to Package controller containers that
+// do not specify their own.
+// The forced image pull policy, if any, applies to all containers created in
+// service of a package, while the default image pull policy, if any, applies
+// to those of packages that do not specify one. The supplied unpack resource
+// requirements apply to the containers of package install jobs. The supplied
+// options configure how both controllers establish the objects output by
+// package install jobs.
+func Setup(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace, tsControllerImage string, allowCore, allowFullDeployment bool, forceImagePullPolicy, defaultImagePullPolicy string, defaultResources, unpackResources corev1.ResourceRequirements, t *install.EstablishTracker, opts ...install.JobCompleterOption) error {
+	ce := install.NewCachingEstablisher()
+	progress := install.WithProgress(func(i v1alpha1.PackageInstaller, done, total int) {
+		l.Debug("established package install job output", "namespace", i.GetNamespace(), "name", i.GetName(), "established", done, "total", total)
+	})
+	piOpts := []install.JobCompleterOption{install.WithCachingEstablisher(ce), progress}
+	cpiOpts := []install.JobCompleterOption{install.WithCachingEstablisher(ce), progress}
+	if t != nil {
+		piOpts = append(piOpts, install.WithEstablishTracker(t, v1alpha1.PackageInstallGroupKind))
+		cpiOpts = append(cpiOpts, install.WithEstablishTracker(t, v1alpha1.ClusterPackageInstallGroupKind))
+	}
+	piOpts = append(piOpts, opts...)
+	cpiOpts = append(cpiOpts, opts...)
+
+	if err := install.SetupPackageInstall(mgr, l, hostControllerNamespace, tsControllerImage, forceImagePullPolicy, defaultImagePullPolicy, unpackResources, piOpts...); err != nil {
 		return err
 	}
 
-	if err := install.SetupClusterPackageInstall(mgr, l, hostControllerNamespace, tsControllerImage); err != nil {
+	if err := install.SetupClusterPackageInstall(mgr, l, hostControllerNamespace, tsControllerImage, forceImagePullPolicy, defaultImagePullPolicy, unpackResources, cpiOpts...); err != nil {
 		return err
 	}
 
 	if err := persona.Setup(mgr, l); err != nil {
 		return nil
 	}
-	if err := pkg.Setup(mgr, l, hostControllerNamespace, allowCore, allowFullDeployment, forceImagePullPolicy); err != nil {
+	if err := pkg.Setup(mgr, l, hostControllerNamespace, allowCore, allowFullDeployment, forceImagePullPolicy, defaultResources); err != nil {
 		return err
 	}
 
@@ -18,6 +18,8 @@ package pkg
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -46,10 +48,9 @@ import (
 )
 
 const (
-	packagesFinalizer             = "finalizer.packages.crossplane.io"
-	labelValueNamespaceMember     = "true"
-	labelValueAggregationEnabled  = "true"
-	labelValueActiveParentPackage = "true"
+	packagesFinalizer            = "finalizer.packages.crossplane.io"
+	labelValueNamespaceMember    = "true"
+	labelValueAggregationEnabled = "true"
 
 	reconcileTimeout      = 1 * time.Minute
 	requeueAfterOnSuccess = 10 * time.Second
@@ -63,6 +64,7 @@ const (
 	errHostAwareModeNotEnabled            = "host aware mode is not enabled"
 	errFailedToPrepareHostAwareDeployment = "failed to prepare host aware package controller deployment"
 	errFailedToCreateDeployment           = "failed to create deployment"
+	errFailedToUpdateDeployment           = "failed to update deployment"
 	errFailedToGenerateSecretNames        = "failed to generate host secret names"
 
 	errFailedToGetDeployment                    = "failed to get deployment"
@@ -104,12 +106,15 @@ type Reconciler struct {
 	allowCore            bool
 	allowFullDeployment  bool
 	forceImagePullPolicy string
+	defaultResources     corev1.ResourceRequirements
 	log                  logging.Logger
 	factory
 }
 
-// Setup adds a controller that reconciles Packages.
-func Setup(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace string, allowCore, allowFullDeployment bool, forceImagePullPolicy string) error {
+// Setup adds a controller that reconciles Packages. The supplied default
+// resource requirements are used by the containers of any Package controller
+// that does not specify its own.
+func Setup(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace string, allowCore, allowFullDeployment bool, forceImagePullPolicy string, defaultResources corev1.ResourceRequirements) error {
 	name := "packages/" + strings.ToLower(v1alpha1.PackageGroupKind)
 
 	hostKube, _, err := hosted.GetClients()
@@ -129,6 +134,7 @@ func Setup(mgr ctrl.Manager, l logging.Logger, hostControllerNamespace string, a
 		allowCore:            allowCore,
 		allowFullDeployment:  allowFullDeployment,
 		forceImagePullPolicy: forceImagePullPolicy,
+		defaultResources:     defaultResources,
 		factory:              &packageHandlerFactory{},
 		log:                  l.WithValues("controller", name),
 	}
@@ -162,7 +168,7 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return fail(ctx, r.kube, p, err)
 	}
 
-	handler := r.factory.newHandler(r.log, p, r.kube, r.hostKube, r.hostedConfig, r.allowCore, r.allowFullDeployment, r.forceImagePullPolicy)
+	handler := r.factory.newHandler(r.log, p, r.kube, r.hostKube, r.hostedConfig, r.allowCore, r.allowFullDeployment, r.forceImagePullPolicy, r.defaultResources)
 
 	if meta.WasDeleted(p) {
 		return handler.delete(ctx)
@@ -189,17 +195,18 @@ type packageHandler struct {
 	allowCore            bool
 	allowFullDeployment  bool
 	forceImagePullPolicy string
+	defaultResources     corev1.ResourceRequirements
 	ext                  *v1alpha1.Package
 	log                  logging.Logger
 }
 
 type factory interface {
-	newHandler(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string) handler
+	newHandler(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string, corev1.ResourceRequirements) handler
 }
 
 type packageHandlerFactory struct{}
 
-func (f *packageHandlerFactory) newHandler(log logging.Logger, ext *v1alpha1.Package, kube client.Client, hostKube client.Client, hostAwareConfig *hosted.Config, allowCore, allowFullDeployment bool, forceImagePullPolicy string) handler {
+func (f *packageHandlerFactory) newHandler(log logging.Logger, ext *v1alpha1.Package, kube client.Client, hostKube client.Client, hostAwareConfig *hosted.Config, allowCore, allowFullDeployment bool, forceImagePullPolicy string, defaultResources corev1.ResourceRequirements) handler {
 	return &packageHandler{
 		kube:                 kube,
 		hostKube:             hostKube,
@@ -207,6 +214,7 @@ func (f *packageHandlerFactory) newHandler(log logging.Logger, ext *v1alpha1.Pac
 		allowCore:            allowCore,
 		allowFullDeployment:  allowFullDeployment,
 		forceImagePullPolicy: forceImagePullPolicy,
+		defaultResources:     defaultResources,
 		ext:                  ext,
 		log:                  log,
 	}
@@ -259,7 +267,14 @@ func (h *packageHandler) create(ctx context.Context) (reconcile.Result, error) {
 	return requeueOnSuccess, h.kube.Status().Update(ctx, h.ext)
 }
 
+// update rolls out the package's controller when its configuration has
+// changed since its Deployment was created.
 func (h *packageHandler) update(ctx context.Context) (reconcile.Result, error) {
+	if err := h.processDeployment(ctx); err != nil {
+		h.log.Debug("failed to update deployment", "error", err)
+		return fail(ctx, h.kube, h.ext, err)
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -420,13 +435,13 @@ func (h *packageHandler) createMultipleParentLabelsCRDHandler() crdHandler {
 				continue
 			}
 
-			if labels[labelMultiParent] == labelValueActiveParentPackage {
+			if labels[labelMultiParent] == packages.LabelValueActiveParentPackage {
 				continue
 			}
 
 			crdPatch := client.MergeFrom(crds[i].DeepCopy())
 
-			labels[labelMultiParent] = labelValueActiveParentPackage
+			labels[labelMultiParent] = packages.LabelValueActiveParentPackage
 			crds[i].SetLabels(labels)
 
 			h.log.Debug("adding labels for CRD", "labelMultiParent", labelMultiParent, "name", crds[i].GetName())
@@ -473,16 +488,7 @@ func (h *packageHandler) createPersonaClusterRolesCRDHandler() crdHandler {
 			rules := []rbacv1.PolicyRule{}
 
 			for _, crd := range crds {
-				kinds := []string{crd.Spec.Names.Plural}
-
-				if subs := crd.Spec.Subresources; subs != nil {
-					if subs.Status != nil {
-						kinds = append(kinds, crd.Spec.Names.Plural+"/status")
-					}
-					if subs.Scale != nil {
-						kinds = append(kinds, crd.Spec.Names.Plural+"/scale")
-					}
-				}
+				kinds := append([]string{crd.Spec.Names.Plural}, packages.SubresourceKinds(&crd)...)
 
 				rules = append(rules, rbacv1.PolicyRule{
 					APIGroups: []string{crd.Spec.Group},
@@ -869,6 +875,11 @@ func (h *packageHandler) prepareDeployment(d *apps.Deployment) {
 			if h.forceImagePullPolicy != "" {
 				c[i].ImagePullPolicy = corev1.PullPolicy(h.forceImagePullPolicy)
 			}
+			// Containers that specify any resource requirements of their
+			// own are not subject to the defaults.
+			if len(c[i].Resources.Requests) == 0 && len(c[i].Resources.Limits) == 0 {
+				h.defaultResources.DeepCopyInto(&c[i].Resources)
+			}
 			if !h.allowFullDeployment {
 				c[i].SecurityContext = &corev1.SecurityContext{
 					AllowPrivilegeEscalation: &allowPrivilegeEscalation,
@@ -884,6 +895,16 @@ func (h *packageHandler) prepareDeployment(d *apps.Deployment) {
 	d.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
 }
 
+// configHash returns a hash of the supplied deployment spec. A change to any
+// part of the package controller's configuration that is reflected in its
+// deployment spec, including its replica count, changes the hash.
+func configHash(s apps.DeploymentSpec) string {
+	s.Template = *s.Template.DeepCopy()
+	meta.RemoveAnnotations(&s.Template, packages.AnnotationConfigHash)
+	b, _ := json.Marshal(s)
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
 func (h *packageHandler) processDeployment(ctx context.Context) error {
 	if h.ext.Spec.Controller.Deployment == nil {
 		return nil
@@ -892,12 +913,20 @@ func (h *packageHandler) processDeployment(ctx context.Context) error {
 	var err error
 	d := &apps.Deployment{}
 	h.prepareDeployment(d)
+
+	// The config hash covers the pod template rendered for the package, before
+	// it is made host aware. Making it host aware generates new image pull
+	// secret names each time, which would otherwise change the hash on every
+	// reconcile.
+	meta.AddAnnotations(&d.Spec.Template, map[string]string{packages.AnnotationConfigHash: configHash(d.Spec)})
+
 	saRef, saSecretRef := h.hostSARefs(d)
 
 	if err = h.prepareHostAwareDeployment(d, saSecretRef.Name); err != nil {
 		return err
 	}
 
+	desired := d.DeepCopy()
 	err = h.hostKube.Get(ctx, types.NamespacedName{Name: d.GetName(), Namespace: d.GetNamespace()}, d)
 
 	if err != nil {
@@ -910,6 +939,15 @@ func (h *packageHandler) processDeployment(ctx context.Context) error {
 		}
 	}
 
+	// Roll out the controller when its configuration has changed since its
+	// Deployment was last created or updated.
+	if d.Spec.Template.GetAnnotations()[packages.AnnotationConfigHash] != desired.Spec.Template.GetAnnotations()[packages.AnnotationConfigHash] {
+		desired.Spec.DeepCopyInto(&d.Spec)
+		if err := h.hostKube.Update(ctx, d); err != nil {
+			return errors.Wrap(err, errFailedToUpdateDeployment)
+		}
+	}
+
 	gvk := apps.SchemeGroupVersion.WithKind("Deployment")
 	d.SetGroupVersionKind(gvk)
 
@@ -33,6 +33,7 @@ import (
 	rbac "k8s.io/api/rbac/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -221,11 +222,11 @@ func resource(rm ...resourceModifier) *v1alpha1.Package {
 // mockFactory and mockHandler
 // ************************************************************************************************
 type mockFactory struct {
-	MockNewHandler func(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string) handler
+	MockNewHandler func(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string, corev1.ResourceRequirements) handler
 }
 
-func (f *mockFactory) newHandler(log logging.Logger, r *v1alpha1.Package, c client.Client, h client.Client, hc *hosted.Config, allowCore, allowFullDeployment bool, forceImagePullPolicy string) handler {
-	return f.MockNewHandler(log, r, c, nil, nil, allowCore, allowFullDeployment, forceImagePullPolicy)
+func (f *mockFactory) newHandler(log logging.Logger, r *v1alpha1.Package, c client.Client, h client.Client, hc *hosted.Config, allowCore, allowFullDeployment bool, forceImagePullPolicy string, defaultResources corev1.ResourceRequirements) handler {
+	return f.MockNewHandler(log, r, c, nil, nil, allowCore, allowFullDeployment, forceImagePullPolicy, defaultResources)
 }
 
 type mockHandler struct {
@@ -374,7 +375,7 @@ func TestReconcile(t *testing.T) {
 					MockUpdate: test.NewMockUpdateFn(nil),
 				},
 				factory: &mockFactory{
-					MockNewHandler: func(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string) handler {
+					MockNewHandler: func(logging.Logger, *v1alpha1.Package, client.Client, client.Client, *hosted.Config, bool, bool, string, corev1.ResourceRequirements) handler {
 						return &mockHandler{
 							MockSync: func(context.Context) (reconcile.Result, error) {
 								return reconcile.Result{}, nil
@@ -1200,12 +1201,66 @@ func TestProcessDeployment(t *testing.T) {
 							return errors.New("unexpected client GET call")
 						}
 					},
+					MockUpdate: test.NewMockUpdateFn(nil),
 				}
 			},
 			want: want{
 				controllerRef: meta.ReferenceTo(testDep, apps.SchemeGroupVersion.WithKind("Deployment")),
 			},
 		},
+		{
+			name: "DeploymentUpToDate",
+			r:    resource(withControllerSpec(defaultControllerSpec())),
+			clientFunc: func(initObjs ...runtime.Object) client.Client {
+				return &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+						switch o := obj.(type) {
+						case *apps.Deployment:
+							// An up to date deployment has the config hash we
+							// would render. It should not be updated.
+							h := &packageHandler{ext: resource(withControllerSpec(defaultControllerSpec()))}
+							h.prepareDeployment(o)
+							meta.AddAnnotations(&o.Spec.Template, map[string]string{packagespkg.AnnotationConfigHash: configHash(o.Spec)})
+							o.SetUID(uid)
+							return nil
+						default:
+							return errors.New("unexpected client GET call")
+						}
+					},
+					MockUpdate: test.NewMockUpdateFn(errors.New("unexpected client UPDATE call")),
+				}
+			},
+			want: want{
+				controllerRef: &corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Namespace:  namespace,
+					Name:       resourceName + "-controller",
+					UID:        uid,
+				},
+			},
+		},
+		{
+			name: "UpdateDeploymentError",
+			r:    resource(withControllerSpec(defaultControllerSpec())),
+			clientFunc: func(initObjs ...runtime.Object) client.Client {
+				return &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+						switch o := obj.(type) {
+						case *apps.Deployment:
+							testDep.DeepCopyInto(o)
+							return nil
+						default:
+							return errors.New("unexpected client GET call")
+						}
+					},
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				}
+			},
+			want: want{
+				err: errors.Wrap(errBoom, "failed to update deployment"),
+			},
+		},
 		{
 			name: "CreateDeploymentError",
 			r:    resource(withControllerSpec(defaultControllerSpec())),
@@ -1685,6 +1740,14 @@ func TestProcessDeployment(t *testing.T) {
 			}
 
 			if tt.want.d != nil {
+				// The config hash covers the entire pod template rendered for
+				// the package, before it is made host aware.
+				rendered := &apps.Deployment{}
+				(&packageHandler{ext: tt.r, forceImagePullPolicy: tt.forceImagePullPolicy, allowFullDeployment: tt.passFullDeployment}).prepareDeployment(rendered)
+				meta.AddAnnotations(&tt.want.d.Spec.Template, map[string]string{
+					packagespkg.AnnotationConfigHash: configHash(rendered.Spec),
+				})
+
 				got := &apps.Deployment{}
 				assertKubernetesObject(t, g, got, tt.want.d, handler.hostKube)
 			}
@@ -1696,6 +1759,112 @@ func TestProcessDeployment(t *testing.T) {
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	defaults := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: kresource.MustParse("512Mi")},
+	}
+	probe := &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/livez"}}}
+	replicas := int32(3)
+
+	withPackageDeployment := func(dsm deploymentSpecModifier) func(h *packageHandler) {
+		return func(h *packageHandler) { dsm(&h.ext.Spec.Controller.Deployment.Spec) }
+	}
+
+	cases := map[string]struct {
+		reason  string
+		change  func(h *packageHandler)
+		rollout bool
+		check   func(d *apps.Deployment) bool
+	}{
+		"Unchanged": {
+			reason: "The controller deployment should not be rolled out when its configuration is unchanged",
+			change: func(h *packageHandler) {},
+		},
+		"ControllerSpec": {
+			reason: "The controller deployment should be rolled out when the package's controller spec changes",
+			change: withPackageDeployment(func(ds *apps.DeploymentSpec) {
+				ds.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "COOL", Value: "true"}}
+			}),
+			rollout: true,
+			check:   func(d *apps.Deployment) bool { return len(d.Spec.Template.Spec.Containers[0].Env) == 1 },
+		},
+		"Scheduling": {
+			reason: "The controller deployment should be rolled out when its scheduling options change",
+			change: withPackageDeployment(func(ds *apps.DeploymentSpec) {
+				ds.Template.Spec.NodeSelector = map[string]string{"pool": "providers"}
+			}),
+			rollout: true,
+			check:   func(d *apps.Deployment) bool { return d.Spec.Template.Spec.NodeSelector["pool"] == "providers" },
+		},
+		"Probes": {
+			reason: "The controller deployment should be rolled out when its probes change",
+			change: withPackageDeployment(func(ds *apps.DeploymentSpec) {
+				ds.Template.Spec.Containers[0].LivenessProbe = probe
+			}),
+			rollout: true,
+			check: func(d *apps.Deployment) bool {
+				return cmp.Equal(probe, d.Spec.Template.Spec.Containers[0].LivenessProbe)
+			},
+		},
+		"Replicas": {
+			reason: "The controller deployment should be rolled out when its replica count changes",
+			change: withPackageDeployment(func(ds *apps.DeploymentSpec) {
+				ds.Replicas = &replicas
+			}),
+			rollout: true,
+			check:   func(d *apps.Deployment) bool { return d.Spec.Replicas != nil && *d.Spec.Replicas == replicas },
+		},
+		"DefaultResources": {
+			reason:  "The controller deployment should be rolled out when the default resource requirements change",
+			change:  func(h *packageHandler) { h.defaultResources = defaults },
+			rollout: true,
+			check: func(d *apps.Deployment) bool {
+				return cmp.Equal(defaults, d.Spec.Template.Spec.Containers[0].Resources)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ext := resource(withControllerSpec(defaultControllerSpec()))
+			kube := fake.NewFakeClient(ext)
+			h := &packageHandler{kube: kube, hostKube: kube, ext: ext, log: logging.NewNopLogger()}
+
+			if _, err := h.create(ctx); err != nil {
+				t.Fatalf("\n%s\ncreate(...): %s", tc.reason, err)
+			}
+			if h.ext.Status.ControllerRef == nil {
+				t.Fatalf("\n%s\ncreate(...): want controller reference, got none", tc.reason)
+			}
+
+			key := types.NamespacedName{Name: h.ext.Status.ControllerRef.Name, Namespace: h.ext.Status.ControllerRef.Namespace}
+			created := &apps.Deployment{}
+			if err := kube.Get(ctx, key, created); err != nil {
+				t.Fatalf("\n%s\nGet(...): %s", tc.reason, err)
+			}
+
+			tc.change(h)
+			if _, err := h.sync(ctx); err != nil {
+				t.Fatalf("\n%s\nsync(...): %s", tc.reason, err)
+			}
+
+			updated := &apps.Deployment{}
+			if err := kube.Get(ctx, key, updated); err != nil {
+				t.Fatalf("\n%s\nGet(...): %s", tc.reason, err)
+			}
+
+			before := created.Spec.Template.GetAnnotations()[packagespkg.AnnotationConfigHash]
+			after := updated.Spec.Template.GetAnnotations()[packagespkg.AnnotationConfigHash]
+			if rollout := before != after; rollout != tc.rollout {
+				t.Errorf("\n%s\nsync(...): want config hash changed %t, got %t", tc.reason, tc.rollout, rollout)
+			}
+			if tc.check != nil && !tc.check(updated) {
+				t.Errorf("\n%s\nsync(...): the changed configuration was not applied to the deployment", tc.reason)
+			}
+		})
+	}
+}
+
 type objectWithGVK interface {
 	runtime.Object
 	metav1.Object
@@ -1890,6 +2059,83 @@ func TestPackageDelete(t *testing.T) {
 	}
 }
 
+func Test_configHash(t *testing.T) {
+	hash := func(p *v1alpha1.Package) string {
+		d := &apps.Deployment{}
+		(&packageHandler{ext: p}).prepareDeployment(d)
+		return configHash(d.Spec)
+	}
+
+	original := hash(resource(withControllerSpec(defaultControllerSpec())))
+	if diff := cmp.Diff(original, hash(resource(withControllerSpec(defaultControllerSpec())))); diff != "" {
+		t.Errorf("configHash(...): an unchanged config should not change the config hash: -want, +got:\n%s", diff)
+	}
+
+	withEnv := func(ds *apps.DeploymentSpec) {
+		for i := range ds.Template.Spec.Containers {
+			ds.Template.Spec.Containers[i].Env = append(ds.Template.Spec.Containers[i].Env, corev1.EnvVar{Name: "COOL", Value: "true"})
+		}
+	}
+	if changed := hash(resource(withControllerSpec(defaultControllerSpec(withEnv)))); changed == original {
+		t.Errorf("configHash(...): a changed config should change the config hash %q", original)
+	}
+}
+
+func Test_packageHandler_prepareDeployment_defaultResources(t *testing.T) {
+	defaults := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: kresource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: kresource.MustParse("512Mi")},
+	}
+	own := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: kresource.MustParse("1")},
+	}
+	withResources := func(rr corev1.ResourceRequirements) deploymentSpecModifier {
+		return func(ds *apps.DeploymentSpec) {
+			for i := range ds.Template.Spec.Containers {
+				ds.Template.Spec.Containers[i].Resources = rr
+			}
+		}
+	}
+
+	cases := map[string]struct {
+		reason   string
+		defaults corev1.ResourceRequirements
+		ext      *v1alpha1.Package
+		want     corev1.ResourceRequirements
+	}{
+		"NoDefaults": {
+			reason: "Containers should have no resource requirements when none are specified or defaulted",
+			ext:    resource(withControllerSpec(defaultControllerSpec())),
+			want:   corev1.ResourceRequirements{},
+		},
+		"DefaultsApplied": {
+			reason:   "Containers that specify no resource requirements should use the defaults",
+			defaults: defaults,
+			ext:      resource(withControllerSpec(defaultControllerSpec())),
+			want:     defaults,
+		},
+		"OwnRequirementsWin": {
+			reason:   "Containers that specify resource requirements should not use the defaults",
+			defaults: defaults,
+			ext:      resource(withControllerSpec(defaultControllerSpec(withResources(own)))),
+			want:     own,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &packageHandler{ext: tc.ext, defaultResources: tc.defaults}
+			d := &apps.Deployment{}
+			h.prepareDeployment(d)
+			for _, c := range d.Spec.Template.Spec.Containers {
+				if diff := cmp.Diff(tc.want, c.Resources); diff != "" {
+					t.Errorf("\n%s\nprepareDeployment(...): -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
 func Test_packageHandler_prepareHostAwareDeployment(t *testing.T) {
 	type fields struct {
 		kube            client.Client
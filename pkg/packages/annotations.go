@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packages
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+)
+
+// Annotations used to control how the package manager reconciles a resource.
+const (
+	// AnnotationPaused may be set to "true" on a package resource to stop the
+	// package manager from reconciling it. Objects that were already created
+	// are left untouched until the annotation is removed, unless the package
+	// resource is deleted.
+	AnnotationPaused = "crossplane.io/paused"
+
+	// AnnotationForceReestablish may be set, typically to a timestamp, on a
+	// package resource to have the package manager establish the objects it
+	// output again, for example after they were edited by hand. As when they
+	// were first established, the package's pre-activation hook, if any, must
+	// succeed first. The package manager removes the annotation once the
+	// objects are established.
+	AnnotationForceReestablish = "pkg.crossplane.io/force-reestablish"
+
+	// AnnotationForceReestablished records the last value of the
+	// force-reestablish annotation that the package manager handled.
+	AnnotationForceReestablished = "pkg.crossplane.io/force-reestablished"
+
+	// AnnotationConfigHash is set by the package manager on the pod template
+	// of a package controller's Deployment. It records a hash of the
+	// Deployment spec the package manager rendered, so that the Deployment is
+	// updated and rolled out when that configuration changes.
+	AnnotationConfigHash = "packages.crossplane.io/config-hash"
+
+	// AnnotationPackageSHA256 may be set on a PackageInstall or
+	// ClusterPackageInstall whose package is a tarball URL to the hex encoded
+	// SHA256 checksum the tarball must have. The package is not installed if
+	// the fetched tarball does not match.
+	AnnotationPackageSHA256 = "packages.crossplane.io/package-sha256"
+
+	annotationValuePaused = "true"
+)
+
+// IsPaused returns true if the supplied object has reconciliation paused.
+func IsPaused(o metav1.Object) bool {
+	return o.GetAnnotations()[AnnotationPaused] == annotationValuePaused
+}
+
+// ForceReestablish returns the value of the supplied object's
+// force-reestablish annotation, and whether that value is one that still needs
+// to be handled. A value that was already handled need not be handled again.
+func ForceReestablish(o metav1.Object) (string, bool) {
+	v, ok := o.GetAnnotations()[AnnotationForceReestablish]
+	if !ok {
+		return "", false
+	}
+	return v, o.GetAnnotations()[AnnotationForceReestablished] != v
+}
+
+// ForceReestablished records that the supplied value of the supplied object's
+// force-reestablish annotation was handled, and removes the annotation.
+func ForceReestablished(o metav1.Object, v string) {
+	meta.RemoveAnnotations(o, AnnotationForceReestablish)
+	meta.AddAnnotations(o, map[string]string{AnnotationForceReestablished: v})
+}
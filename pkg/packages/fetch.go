@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packages
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// MaxTarballSize is the largest package tarball, in bytes, that a
+// HTTPTarballFetcher will fetch.
+const MaxTarballSize = 64 << 20
+
+const (
+	errFetch            = "cannot fetch package tarball"
+	errReadTarball      = "cannot read package tarball"
+	errTarballTooLarge  = "package tarball is too large"
+	errFmtStatus        = "cannot fetch package tarball: unexpected HTTP status %q"
+	errFmtContentType   = "unsupported package tarball content type %q: packages fetched over HTTP must be gzipped tar archives"
+	errFmtChecksum      = "package tarball has SHA256 checksum %s, not %s"
+	errFmtUnsafePath    = "package tarball contains unsafe path %q"
+	errFmtWriteContents = "cannot write package contents to %q"
+)
+
+// tarballContentTypes are the content types a package tarball may be served
+// with. Many servers and object stores serve gzipped tarballs as arbitrary
+// binary data, so application/octet-stream is accepted too.
+var tarballContentTypes = map[string]bool{
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-gtar":           true,
+	"application/x-tgz":            true,
+	"application/x-compressed-tar": true,
+	"application/octet-stream":     true,
+}
+
+// IsTarballURL returns true if the supplied package is a URL from which a
+// package tarball can be fetched, rather than an image.
+func IsTarballURL(pkg string) bool {
+	return strings.HasPrefix(pkg, "http://") || strings.HasPrefix(pkg, "https://")
+}
+
+// A Fetcher fetches package contents from the supplied source and writes them
+// to the supplied directory.
+type Fetcher interface {
+	Fetch(ctx context.Context, src, dir string) error
+}
+
+// An HTTPTarballFetcher fetches package contents from a gzipped tarball served
+// over HTTP(S). The tarball is expected to contain the package's .registry
+// directory at its root, as a package image does.
+type HTTPTarballFetcher struct {
+	// Client is used to fetch the tarball.
+	Client *http.Client
+
+	// Fs is the filesystem package contents are written to.
+	Fs afero.Fs
+
+	// SHA256 is the hex encoded checksum the tarball must have, if any.
+	SHA256 string
+}
+
+// Fetch the gzipped tarball at the supplied URL and unpack it into the
+// supplied directory. Nothing is unpacked unless the tarball matches the
+// expected checksum, if any.
+func (f *HTTPTarballFetcher) Fetch(ctx context.Context, url, dir string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, errFetch)
+	}
+
+	rsp, err := f.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, errFetch)
+	}
+	defer rsp.Body.Close() // nolint:errcheck
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.Errorf(errFmtStatus, rsp.Status)
+	}
+
+	if ct := rsp.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || !tarballContentTypes[mt] {
+			return errors.Errorf(errFmtContentType, ct)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	n, err := io.Copy(b, io.LimitReader(rsp.Body, MaxTarballSize+1))
+	if err != nil {
+		return errors.Wrap(err, errReadTarball)
+	}
+	if n > MaxTarballSize {
+		return errors.New(errTarballTooLarge)
+	}
+
+	if f.SHA256 != "" {
+		sum := sha256.Sum256(b.Bytes())
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, f.SHA256) {
+			return errors.Errorf(errFmtChecksum, got, f.SHA256)
+		}
+	}
+
+	return errors.Wrap(Untar(f.Fs, b, dir), errReadTarball)
+}
+
+// Untar unpacks the supplied gzipped tarball into the supplied directory.
+// Only directories and regular files are unpacked. Entries that would be
+// unpacked outside the directory are rejected.
+func Untar(fs afero.Fs, r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close() // nolint:errcheck
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(h.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return errors.Errorf(errFmtUnsafePath, h.Name)
+		}
+		path := filepath.Join(dir, name)
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := fs.MkdirAll(path, 0755); err != nil {
+				return errors.Wrapf(err, errFmtWriteContents, path)
+			}
+		case tar.TypeReg:
+			if err := writeFile(fs, path, tr, os.FileMode(h.Mode).Perm()); err != nil {
+				return errors.Wrapf(err, errFmtWriteContents, path)
+			}
+		}
+	}
+}
+
+func writeFile(fs afero.Fs, path string, r io.Reader, mode os.FileMode) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := fs.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packages
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	b := &bytes.Buffer{}
+	gw := gzip.NewWriter(b)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestIsTarballURL(t *testing.T) {
+	cases := map[string]bool{
+		"crossplane/provider-gcp:v0.10.0":           false,
+		"registry.crossplane.io/crossplane/app":     false,
+		"http://example.org/packages/app.tgz":       true,
+		"https://example.org/packages/app.tar.gz":   true,
+		"ftp://example.org/packages/app.tar.gz":     false,
+		"example.org/https://packages/app.tar.gz":   false,
+		"https://example.org/packages/app?v=v0.1.0": true,
+	}
+
+	for pkg, want := range cases {
+		t.Run(pkg, func(t *testing.T) {
+			if diff := cmp.Diff(want, IsTarballURL(pkg)); diff != "" {
+				t.Errorf("IsTarballURL(%q): -want, +got:\n%s", pkg, diff)
+			}
+		})
+	}
+}
+
+func TestHTTPTarballFetcherFetch(t *testing.T) {
+	contents := tarball(t, map[string]string{".registry/app.yaml": "name: cool"})
+	sum := sha256.Sum256(contents)
+	checksum := hex.EncodeToString(sum[:])
+
+	serve := func(contentType string, body []byte) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write(body)
+		}))
+	}
+
+	type want struct {
+		files map[string]string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		server *httptest.Server
+		sha256 string
+		want   want
+	}{
+		"Unpacked": {
+			reason: "A gzipped tarball should be unpacked into the supplied directory",
+			server: serve("application/gzip", contents),
+			want:   want{files: map[string]string{"/ext-pkg/.registry/app.yaml": "name: cool"}},
+		},
+		"ChecksumMatches": {
+			reason: "A gzipped tarball should be unpacked when it matches the expected checksum",
+			server: serve("application/octet-stream", contents),
+			sha256: checksum,
+			want:   want{files: map[string]string{"/ext-pkg/.registry/app.yaml": "name: cool"}},
+		},
+		"ChecksumMismatch": {
+			reason: "A gzipped tarball should not be unpacked when it does not match the expected checksum",
+			server: serve("application/gzip", contents),
+			sha256: "c00l",
+			want:   want{err: errors.Errorf(errFmtChecksum, checksum, "c00l")},
+		},
+		"UnsupportedContentType": {
+			reason: "Content that is not a gzipped tarball should be rejected",
+			server: serve("text/html; charset=utf-8", []byte("<html></html>")),
+			want:   want{err: errors.Errorf(errFmtContentType, "text/html; charset=utf-8")},
+		},
+		"NotFound": {
+			reason: "An unsuccessful response should be rejected",
+			server: httptest.NewServer(http.NotFoundHandler()),
+			want:   want{err: errors.Errorf(errFmtStatus, "404 Not Found")},
+		},
+		"UnsafePath": {
+			reason: "A tarball that would unpack files outside the supplied directory should be rejected",
+			server: serve("application/gzip", tarball(t, map[string]string{"../../etc/passwd": "root"})),
+			want:   want{err: errors.Wrap(errors.Errorf(errFmtUnsafePath, "../../etc/passwd"), errReadTarball)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer tc.server.Close()
+
+			fs := afero.NewMemMapFs()
+			f := &HTTPTarballFetcher{Client: tc.server.Client(), Fs: fs, SHA256: tc.sha256}
+			err := f.Fetch(context.Background(), tc.server.URL, "/ext-pkg")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFetch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			for path, want := range tc.want.files {
+				got, err := afero.ReadFile(fs, path)
+				if err != nil {
+					t.Fatalf("\n%s\nFetch(...): %s", tc.reason, err)
+				}
+				if diff := cmp.Diff(want, string(got)); diff != "" {
+					t.Errorf("\n%s\nFetch(...): -want %s, +got %s:\n%s", tc.reason, path, path, diff)
+				}
+			}
+		})
+	}
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package packages
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/pkg/errors"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane/pkg/packages/truncate"
 )
@@ -58,6 +62,10 @@ const (
 	preserveNSLength = 32
 )
 
+// LabelValueActiveParentPackage is the value of a multi-parent label on a CRD
+// whose parent package is active.
+const LabelValueActiveParentPackage = "true"
+
 // KindlyIdentifier implementations provide the means to access the Name,
 // Namespace, GVK, and UID of a resource
 type KindlyIdentifier interface {
@@ -145,3 +153,20 @@ func HasPrefixedLabel(obj metav1.Object, prefixes ...string) bool {
 	}
 	return false
 }
+
+// ControlledCRDs returns the CRDs whose controller owner reference is the
+// supplied parent package revision, for example so that RBAC can be aggregated
+// for them.
+func ControlledCRDs(ctx context.Context, c client.Reader, parent metav1.Object) ([]apiextensions.CustomResourceDefinition, error) {
+	l := &apiextensions.CustomResourceDefinitionList{}
+	if err := c.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, "failed to list CRDs")
+	}
+	crds := []apiextensions.CustomResourceDefinition{}
+	for _, crd := range l.Items {
+		if metav1.IsControlledBy(&crd, parent) {
+			crds = append(crds, crd)
+		}
+	}
+	return crds, nil
+}
@@ -17,13 +17,21 @@ limitations under the License.
 package packages
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
 var (
@@ -302,3 +310,63 @@ func TestMultiParentLabelPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestControlledCRDs(t *testing.T) {
+	parent := resource(namespace, resourceName, uidString)
+	other := resource(namespace, "other-resource", "another-uuid")
+
+	crd := func(name string, labels map[string]string, refs ...metav1.OwnerReference) *apiextensions.CustomResourceDefinition {
+		return &apiextensions.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, OwnerReferences: refs}}
+	}
+	ref := func(owner metav1.Object, controller bool) metav1.OwnerReference {
+		return metav1.OwnerReference{Name: owner.GetName(), UID: owner.GetUID(), Controller: &controller}
+	}
+	labeled := map[string]string{
+		LabelKubernetesManagedBy: LabelValuePackageManager,
+		MultiParentLabel(parent): LabelValueActiveParentPackage,
+	}
+
+	s := runtime.NewScheme()
+	_ = apiextensions.AddToScheme(s)
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		client  client.Reader
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "MixOfOwnedAndUnowned",
+			client: fake.NewFakeClientWithScheme(s,
+				crd("controlled", labeled, ref(parent, true)),
+				crd("controlled-unlabeled", nil, ref(parent, true)),
+				crd("owned-not-controlled", labeled, ref(parent, false)),
+				crd("controlled-by-other", labeled, ref(other, true), ref(parent, false)),
+				crd("labeled-unowned", labeled),
+				crd("unowned", nil),
+			),
+			want: []string{"controlled", "controlled-unlabeled"},
+		},
+		{
+			name:    "ListError",
+			client:  &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			wantErr: errors.Wrap(errBoom, "failed to list CRDs"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crds, err := ControlledCRDs(context.Background(), tt.client, parent)
+			if diff := cmp.Diff(tt.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ControlledCRDs() -want error, +got error:\n%v", diff)
+			}
+			var got []string
+			for _, c := range crds {
+				got = append(got, c.GetName())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ControlledCRDs() -want, +got:\n%v", diff)
+			}
+		})
+	}
+}
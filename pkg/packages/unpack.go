@@ -56,6 +56,11 @@ const (
 	// StackDefinition controllers deployment to find the StackDefinition
 	StackDefinitionNameEnv = "SD_NAME"
 
+	// LeaderElectionEnv is an environment variable set to "true" in the
+	// containers of a package controller Deployment that runs more than one
+	// replica, telling them to elect a leader.
+	LeaderElectionEnv = "LEADER_ELECTION"
+
 	// PackageImageEnv is an environment variable used by the unpack job to select
 	// the stack version if there is no version provided in the application
 	// metadata.
@@ -466,6 +471,34 @@ func generateRBAC(apiKinds []string, apiGroup string, verbs []string) rbacv1.Pol
 	}
 }
 
+// SubresourceKinds returns the RBAC resources of the status and scale
+// subresources the supplied CRD declares, either for all of its versions or for
+// any one of them.
+func SubresourceKinds(crd *apiextensions.CustomResourceDefinition) []string {
+	subs := []*apiextensions.CustomResourceSubresources{crd.Spec.Subresources}
+	for _, v := range crd.Spec.Versions {
+		subs = append(subs, v.Subresources)
+	}
+
+	status, scale := false, false
+	for _, s := range subs {
+		if s == nil {
+			continue
+		}
+		status = status || s.Status != nil
+		scale = scale || s.Scale != nil
+	}
+
+	kinds := []string{}
+	if status {
+		kinds = append(kinds, crd.Spec.Names.Plural+"/status")
+	}
+	if scale {
+		kinds = append(kinds, crd.Spec.Names.Plural+"/scale")
+	}
+	return kinds
+}
+
 // applyRules adds RBAC rules to the Package for standard Package needs and to fulfill dependencies
 func (sp *PackagePackage) applyRules() (v1alpha1.PermissionsSpec, error) {
 	// standard rules that all Packages get
@@ -476,16 +509,8 @@ func (sp *PackagePackage) applyRules() (v1alpha1.PermissionsSpec, error) {
 	orderedKeys := orderPackageCRDKeys(sp.CRDs)
 	for _, k := range orderedKeys {
 		crd := sp.CRDs[k]
-		kinds := []string{crd.Spec.Names.Plural}
+		kinds := append([]string{crd.Spec.Names.Plural}, SubresourceKinds(&crd)...)
 		verbs := allVerbs
-		if subs := crd.Spec.Subresources; subs != nil {
-			if subs.Status != nil {
-				kinds = append(kinds, crd.Spec.Names.Plural+"/status")
-			}
-			if subs.Scale != nil {
-				kinds = append(kinds, crd.Spec.Names.Plural+"/scale")
-			}
-		}
 
 		// For the package controller to set a controller owner reference on CRs
 		// that it owns, in some settings (OpenShift 4.3), it is necessary to
@@ -27,6 +27,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -1484,3 +1486,63 @@ func TestIsMetadataApplicableToCRD(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyRulesSubresources(t *testing.T) {
+	crd := func(subs *apiextensions.CustomResourceSubresources, versions ...apiextensions.CustomResourceDefinitionVersion) apiextensions.CustomResourceDefinition {
+		c := apiextensions.CustomResourceDefinition{}
+		c.Spec.Group = "samples.upbound.io"
+		c.Spec.Names.Plural = "mytypes"
+		c.Spec.Subresources = subs
+		c.Spec.Versions = versions
+		return c
+	}
+	status := &apiextensions.CustomResourceSubresources{Status: &apiextensions.CustomResourceSubresourceStatus{}}
+	scale := &apiextensions.CustomResourceSubresources{Scale: &apiextensions.CustomResourceSubresourceScale{}}
+
+	tests := []struct {
+		name string
+		crd  apiextensions.CustomResourceDefinition
+		want []string
+	}{
+		{
+			name: "NoSubresources",
+			crd:  crd(nil, apiextensions.CustomResourceDefinitionVersion{Name: "v1alpha1"}),
+			want: []string{"mytypes", "mytypes/finalizers"},
+		},
+		{
+			name: "TopLevelSubresources",
+			crd:  crd(&apiextensions.CustomResourceSubresources{Status: status.Status, Scale: scale.Scale}),
+			want: []string{"mytypes", "mytypes/status", "mytypes/scale", "mytypes/finalizers"},
+		},
+		{
+			name: "PerVersionSubresources",
+			crd: crd(nil,
+				apiextensions.CustomResourceDefinitionVersion{Name: "v1alpha1", Subresources: status},
+				apiextensions.CustomResourceDefinitionVersion{Name: "v1alpha2", Subresources: scale},
+				apiextensions.CustomResourceDefinitionVersion{Name: "v1alpha3", Subresources: status},
+			),
+			want: []string{"mytypes", "mytypes/status", "mytypes/scale", "mytypes/finalizers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := NewPackagePackage("", "", logging.NewNopLogger())
+			sp.CRDs["mytypes.samples.upbound.io"] = tt.crd
+
+			got, err := sp.applyRules()
+			if err != nil {
+				t.Fatalf("applyRules(): %s", err)
+			}
+			want := append(append([]rbacv1.PolicyRule{}, PackageCoreRBACRules...), rbacv1.PolicyRule{
+				APIGroups:     []string{"samples.upbound.io"},
+				ResourceNames: []string{},
+				Resources:     tt.want,
+				Verbs:         []string{"*"},
+			})
+			if diff := cmp.Diff(want, got.Rules); diff != "" {
+				t.Errorf("applyRules() -want, +got:\n%v", diff)
+			}
+		})
+	}
+}